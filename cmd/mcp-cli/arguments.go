@@ -0,0 +1,422 @@
+// Copyright 2025 MCP CLI Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/justinwongcn/go-mcp-cli/pkg/client"
+)
+
+// argumentErrors 收集参数校验过程中发现的所有问题
+type argumentErrors []string
+
+func (e argumentErrors) Error() string {
+	return "invalid tool arguments:\n  - " + strings.Join(e, "\n  - ")
+}
+
+// buildToolArguments 获取工具的输入 schema，并据此将 key=value 参数转换为对应类型。
+// base 为通过 --json 传入的基础参数，key=value 参数会覆盖其中的同名字段。
+func buildToolArguments(ctx context.Context, cli *client.MCPClient, toolName string, base map[string]any, pairs []string) (map[string]any, error) {
+	tool, err := cli.GetTool(ctx, toolName)
+	if err != nil {
+		return nil, requestErrorf("failed to look up tool: %w", err)
+	}
+
+	schema, err := schemaMap(tool.InputSchema)
+	if err != nil {
+		return nil, fmt.Errorf("invalid input schema for %s: %w", toolName, err)
+	}
+
+	args := base
+	if args == nil {
+		args = make(map[string]any)
+	}
+	if err := applyArguments(args, schema, pairs); err != nil {
+		return nil, err
+	}
+	return args, nil
+}
+
+// loadJSONArguments 从 --json 或 --json-file 读取工具参数，"-" 表示从标准输入读取
+func loadJSONArguments(jsonArg, jsonFile string) (map[string]any, error) {
+	if jsonArg != "" && jsonFile != "" {
+		return nil, fmt.Errorf("--json and --json-file cannot be used together")
+	}
+
+	var data []byte
+	var source string
+	var err error
+	switch {
+	case jsonArg == "-" || jsonFile == "-":
+		source = "stdin"
+		data, err = io.ReadAll(os.Stdin)
+	case jsonFile != "":
+		source = jsonFile
+		data, err = os.ReadFile(jsonFile)
+	case jsonArg != "":
+		source = "--json"
+		data = []byte(jsonArg)
+	default:
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read arguments from %s: %w", source, err)
+	}
+
+	var args map[string]any
+	if err := json.Unmarshal(data, &args); err != nil {
+		return nil, fmt.Errorf("failed to parse arguments from %s: %w", source, err)
+	}
+	if args == nil {
+		return nil, fmt.Errorf("arguments from %s must be a JSON object", source)
+	}
+	return args, nil
+}
+
+// schemaMap 将任意形式的 JSON Schema 转换为 map 表示
+func schemaMap(schema any) (map[string]any, error) {
+	if schema == nil {
+		return nil, nil
+	}
+	if m, ok := schema.(map[string]any); ok {
+		return m, nil
+	}
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]any
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// applyArguments 按 schema 将 key=value 参数写入 args，并检查未知键和缺失的必填项。
+// 点号分隔的键（如 a.b=1）写入嵌套对象，键本身是 schema 中带点号的属性名时按原样使用；
+// 数组类型的键可以重复出现以追加元素。
+func applyArguments(args map[string]any, schema map[string]any, pairs []string) error {
+	var errs argumentErrors
+	seen := make(map[string]bool)
+
+	for _, key := range sortedKeys(args) {
+		if _, err := propertySchema(schema, []string{key}); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	for _, pair := range pairs {
+		parts := parseArg(pair)
+		if len(parts) != 2 || parts[0] == "" {
+			errs = append(errs, fmt.Sprintf("argument %q is not in key=value form", pair))
+			continue
+		}
+		key, raw := parts[0], parts[1]
+
+		path := splitArgKey(schema, key)
+		propSchema, err := propertySchema(schema, path)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+
+		parent, err := ensureParent(args, path)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		leaf := path[len(path)-1]
+
+		if schemaAllowsType(propSchema, "array") && !strings.HasPrefix(strings.TrimSpace(raw), "[") {
+			item, err := coerceValue(key, raw, subSchema(propSchema, "items"))
+			if err != nil {
+				errs = append(errs, err.Error())
+				continue
+			}
+			// 第一次出现时覆盖 --json 中的值，之后重复出现则追加
+			var existing []any
+			if seen[key] {
+				existing, _ = parent[leaf].([]any)
+			}
+			parent[leaf] = append(existing, item)
+			seen[key] = true
+			continue
+		}
+
+		if seen[key] {
+			errs = append(errs, fmt.Sprintf("argument %q specified more than once", key))
+			continue
+		}
+		value, err := coerceValue(key, raw, propSchema)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		parent[leaf] = value
+		seen[key] = true
+	}
+
+	errs = append(errs, missingRequired("", args, schema)...)
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// splitArgKey 将点号分隔的键拆成属性路径。每一层优先匹配 schema 中最长的属性名，
+// 因此 schema 声明了 a.b 属性时 a.b=1 写入 a.b，而不是嵌套对象 a 的 b。
+func splitArgKey(schema map[string]any, key string) []string {
+	parts := strings.Split(key, ".")
+	var path []string
+	current := schema
+	for len(parts) > 0 {
+		props, _ := current["properties"].(map[string]any)
+		n := 1
+		for i := len(parts); i > 1; i-- {
+			if _, ok := props[strings.Join(parts[:i], ".")]; ok {
+				n = i
+				break
+			}
+		}
+		name := strings.Join(parts[:n], ".")
+		path = append(path, name)
+		parts = parts[n:]
+		current, _ = props[name].(map[string]any)
+	}
+	return path
+}
+
+// propertySchema 沿着点号路径查找属性对应的 schema
+func propertySchema(schema map[string]any, path []string) (map[string]any, error) {
+	current := schema
+	for i, part := range path {
+		if current == nil {
+			return nil, nil
+		}
+		props, _ := current["properties"].(map[string]any)
+		if prop, ok := props[part].(map[string]any); ok {
+			current = prop
+			continue
+		}
+		if extra, ok := current["additionalProperties"].(map[string]any); ok {
+			current = extra
+			continue
+		}
+		if props == nil && !isFalse(current["additionalProperties"]) {
+			// schema 未声明属性，不做约束
+			return nil, nil
+		}
+		if extra, ok := current["additionalProperties"].(bool); ok && extra {
+			current = nil
+			continue
+		}
+		name := strings.Join(path[:i+1], ".")
+		if len(props) > 0 {
+			return nil, fmt.Errorf("unknown argument %q (known: %s)", name, strings.Join(sortedKeys(props), ", "))
+		}
+		return nil, fmt.Errorf("unknown argument %q", name)
+	}
+	return current, nil
+}
+
+// ensureParent 确保点号路径上的中间对象存在，并返回叶子节点所在的对象
+func ensureParent(args map[string]any, path []string) (map[string]any, error) {
+	current := args
+	for i, part := range path[:len(path)-1] {
+		next, exists := current[part]
+		if !exists {
+			child := make(map[string]any)
+			current[part] = child
+			current = child
+			continue
+		}
+		child, ok := next.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("argument %q is not an object", strings.Join(path[:i+1], "."))
+		}
+		current = child
+	}
+	return current, nil
+}
+
+// coerceValue 将字符串值转换为 schema 声明的类型
+func coerceValue(key, raw string, schema map[string]any) (any, error) {
+	types := schemaTypes(schema)
+	if len(types) == 0 {
+		// 未声明类型时，JSON 对象和数组字面量按 JSON 解析，其余保持字符串
+		trimmed := strings.TrimSpace(raw)
+		if strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+			var v any
+			if err := json.Unmarshal([]byte(trimmed), &v); err == nil {
+				return v, nil
+			}
+		}
+		return raw, nil
+	}
+
+	var lastErr error
+	for _, typ := range types {
+		value, err := coerceToType(raw, typ)
+		if err == nil {
+			return value, nil
+		}
+		lastErr = err
+	}
+	return nil, fmt.Errorf("argument %q: %v", key, lastErr)
+}
+
+// coerceToType 将字符串值转换为指定的 JSON Schema 类型
+func coerceToType(raw, typ string) (any, error) {
+	switch typ {
+	case "string":
+		return raw, nil
+	case "integer":
+		v, err := strconv.ParseInt(strings.TrimSpace(raw), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("expected integer, got %q", raw)
+		}
+		return v, nil
+	case "number":
+		v, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+		if err != nil {
+			return nil, fmt.Errorf("expected number, got %q", raw)
+		}
+		return v, nil
+	case "boolean":
+		v, err := strconv.ParseBool(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf("expected boolean, got %q", raw)
+		}
+		return v, nil
+	case "null":
+		if strings.TrimSpace(raw) != "null" {
+			return nil, fmt.Errorf("expected null, got %q", raw)
+		}
+		return nil, nil
+	case "array":
+		var v []any
+		if err := json.Unmarshal([]byte(raw), &v); err != nil {
+			return nil, fmt.Errorf("expected JSON array, got %q", raw)
+		}
+		return v, nil
+	case "object":
+		var v map[string]any
+		if err := json.Unmarshal([]byte(raw), &v); err != nil {
+			return nil, fmt.Errorf("expected JSON object, got %q", raw)
+		}
+		return v, nil
+	default:
+		return raw, nil
+	}
+}
+
+// missingRequired 递归检查已提供对象中缺失的必填属性
+func missingRequired(prefix string, args map[string]any, schema map[string]any) []string {
+	if schema == nil {
+		return nil
+	}
+
+	var missing []string
+	required, _ := schema["required"].([]any)
+	for _, r := range required {
+		name, ok := r.(string)
+		if !ok {
+			continue
+		}
+		if _, exists := args[name]; !exists {
+			missing = append(missing, fmt.Sprintf("missing required argument %q", prefix+name))
+		}
+	}
+
+	props, _ := schema["properties"].(map[string]any)
+	for _, name := range sortedKeys(props) {
+		child, ok := args[name].(map[string]any)
+		if !ok {
+			continue
+		}
+		propSchema, _ := props[name].(map[string]any)
+		missing = append(missing, missingRequired(prefix+name+".", child, propSchema)...)
+	}
+	return missing
+}
+
+// schemaTypes 返回 schema 声明的类型列表，非 null 类型优先
+func schemaTypes(schema map[string]any) []string {
+	if schema == nil {
+		return nil
+	}
+
+	var types []string
+	switch t := schema["type"].(type) {
+	case string:
+		types = append(types, t)
+	case []any:
+		for _, v := range t {
+			if s, ok := v.(string); ok {
+				types = append(types, s)
+			}
+		}
+	}
+
+	// null 放到最后，避免字符串 "null" 之外的值被错误匹配
+	sort.SliceStable(types, func(i, j int) bool {
+		return types[j] == "null" && types[i] != "null"
+	})
+	return types
+}
+
+// schemaAllowsType 检查 schema 是否允许指定类型
+func schemaAllowsType(schema map[string]any, typ string) bool {
+	for _, t := range schemaTypes(schema) {
+		if t == typ {
+			return true
+		}
+	}
+	return false
+}
+
+// subSchema 获取 schema 中指定关键字对应的子 schema
+func subSchema(schema map[string]any, keyword string) map[string]any {
+	if schema == nil {
+		return nil
+	}
+	sub, _ := schema[keyword].(map[string]any)
+	return sub
+}
+
+// isFalse 判断 schema 关键字是否显式设置为 false
+func isFalse(v any) bool {
+	b, ok := v.(bool)
+	return ok && !b
+}
+
+// sortedKeys 返回按字母排序的 map 键
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
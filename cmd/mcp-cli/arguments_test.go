@@ -0,0 +1,229 @@
+// Copyright 2025 MCP CLI Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCoerceValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  string
+		raw     string
+		want    any
+		wantErr bool
+	}{
+		{name: "string", schema: `{"type":"string"}`, raw: "42", want: "42"},
+		{name: "integer", schema: `{"type":"integer"}`, raw: " 42 ", want: int64(42)},
+		{name: "integer rejects float", schema: `{"type":"integer"}`, raw: "4.2", wantErr: true},
+		{name: "number", schema: `{"type":"number"}`, raw: "4.5", want: 4.5},
+		{name: "boolean", schema: `{"type":"boolean"}`, raw: "true", want: true},
+		{name: "boolean rejects word", schema: `{"type":"boolean"}`, raw: "yes", wantErr: true},
+		{name: "null", schema: `{"type":"null"}`, raw: "null", want: nil},
+		{name: "array", schema: `{"type":"array"}`, raw: `["a",1]`, want: []any{"a", float64(1)}},
+		{name: "object", schema: `{"type":"object"}`, raw: `{"a":1}`, want: map[string]any{"a": float64(1)}},
+		{name: "object rejects text", schema: `{"type":"object"}`, raw: "a=1", wantErr: true},
+		{name: "union tries non-null first", schema: `{"type":["null","integer"]}`, raw: "7", want: int64(7)},
+		{name: "union falls back to null", schema: `{"type":["integer","null"]}`, raw: "null", want: nil},
+		{name: "union of integer and string", schema: `{"type":["integer","string"]}`, raw: "abc", want: "abc"},
+		{name: "untyped keeps string", schema: `{}`, raw: "42", want: "42"},
+		{name: "untyped parses JSON object", schema: `{}`, raw: `{"a":true}`, want: map[string]any{"a": true}},
+		{name: "untyped keeps invalid JSON", schema: `{}`, raw: "[oops", want: "[oops"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := coerceValue("key", tt.raw, mustSchema(t, tt.schema))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("coerceValue(%q) = %#v, want error", tt.raw, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("coerceValue(%q): %v", tt.raw, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("coerceValue(%q) = %#v, want %#v", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyArguments(t *testing.T) {
+	schema := `{
+		"type": "object",
+		"properties": {
+			"limit": {"type": "integer"},
+			"tags": {"type": "array", "items": {"type": "string"}},
+			"ids": {"type": "array", "items": {"type": "integer"}},
+			"user.name": {"type": "string"},
+			"filter": {
+				"type": "object",
+				"properties": {
+					"status": {"type": "string"},
+					"max.age": {"type": "integer"}
+				},
+				"required": ["status"]
+			},
+			"labels": {"type": "object", "additionalProperties": {"type": "integer"}}
+		},
+		"required": ["limit"],
+		"additionalProperties": false
+	}`
+
+	tests := []struct {
+		name    string
+		base    map[string]any
+		pairs   []string
+		want    map[string]any
+		wantErr []string
+	}{
+		{
+			name:  "scalar",
+			pairs: []string{"limit=5"},
+			want:  map[string]any{"limit": int64(5)},
+		},
+		{
+			name:  "repeated array key appends",
+			pairs: []string{"limit=1", "tags=a", "tags=b", "ids=3"},
+			want:  map[string]any{"limit": int64(1), "tags": []any{"a", "b"}, "ids": []any{int64(3)}},
+		},
+		{
+			name:  "JSON array literal",
+			pairs: []string{"limit=1", `tags=["x","y"]`},
+			want:  map[string]any{"limit": int64(1), "tags": []any{"x", "y"}},
+		},
+		{
+			name:  "array flag replaces JSON base",
+			base:  map[string]any{"limit": 1, "tags": []any{"old"}},
+			pairs: []string{"tags=new"},
+			want:  map[string]any{"limit": 1, "tags": []any{"new"}},
+		},
+		{
+			name:  "dotted key builds nested object",
+			pairs: []string{"limit=1", "filter.status=open"},
+			want:  map[string]any{"limit": int64(1), "filter": map[string]any{"status": "open"}},
+		},
+		{
+			name:  "dotted property name is used as-is",
+			pairs: []string{"limit=1", "user.name=ann"},
+			want:  map[string]any{"limit": int64(1), "user.name": "ann"},
+		},
+		{
+			name:  "nested dotted property name",
+			pairs: []string{"limit=1", "filter.status=open", "filter.max.age=3"},
+			want:  map[string]any{"limit": int64(1), "filter": map[string]any{"status": "open", "max.age": int64(3)}},
+		},
+		{
+			name:  "additionalProperties schema",
+			pairs: []string{"limit=1", "labels.p1=2"},
+			want:  map[string]any{"limit": int64(1), "labels": map[string]any{"p1": int64(2)}},
+		},
+		{
+			name:    "unknown argument",
+			pairs:   []string{"limit=1", "user.age=3"},
+			wantErr: []string{`unknown argument "user"`},
+		},
+		{
+			name:    "missing required",
+			pairs:   []string{"filter.max.age=3"},
+			wantErr: []string{`missing required argument "limit"`, `missing required argument "filter.status"`},
+		},
+		{
+			name:    "duplicate scalar",
+			pairs:   []string{"limit=1", "limit=2"},
+			wantErr: []string{`argument "limit" specified more than once`},
+		},
+		{
+			name:    "not key=value",
+			pairs:   []string{"limit=1", "oops"},
+			wantErr: []string{`argument "oops" is not in key=value form`},
+		},
+		{
+			name:    "type mismatch",
+			pairs:   []string{"limit=ten"},
+			wantErr: []string{`argument "limit": expected integer`},
+		},
+		{
+			name:    "unknown key in JSON base",
+			base:    map[string]any{"limit": 1, "extra": true},
+			wantErr: []string{`unknown argument "extra"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := tt.base
+			if args == nil {
+				args = make(map[string]any)
+			}
+			err := applyArguments(args, mustSchema(t, schema), tt.pairs)
+			if len(tt.wantErr) > 0 {
+				if err == nil {
+					t.Fatalf("applyArguments(%q) = %#v, want error", tt.pairs, args)
+				}
+				for _, want := range tt.wantErr {
+					if !strings.Contains(err.Error(), want) {
+						t.Errorf("error %q does not contain %q", err, want)
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("applyArguments(%q): %v", tt.pairs, err)
+			}
+			if !reflect.DeepEqual(args, tt.want) {
+				t.Errorf("applyArguments(%q) = %#v, want %#v", tt.pairs, args, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitArgKey(t *testing.T) {
+	schema := mustSchema(t, `{"properties": {
+		"a.b": {"type": "string"},
+		"a": {"properties": {"b.c": {}, "b": {"properties": {"c": {}}}}}
+	}}`)
+
+	tests := []struct {
+		key  string
+		want []string
+	}{
+		{key: "a.b", want: []string{"a.b"}},
+		{key: "a.b.c", want: []string{"a.b", "c"}},
+		{key: "a.x", want: []string{"a", "x"}},
+		{key: "x.y.z", want: []string{"x", "y", "z"}},
+		{key: "plain", want: []string{"plain"}},
+	}
+	for _, tt := range tests {
+		if got := splitArgKey(schema, tt.key); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitArgKey(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+}
+
+func mustSchema(t *testing.T, raw string) map[string]any {
+	t.Helper()
+	var schema map[string]any
+	if err := json.Unmarshal([]byte(raw), &schema); err != nil {
+		t.Fatalf("invalid schema: %v", err)
+	}
+	return schema
+}
@@ -0,0 +1,132 @@
+// Copyright 2025 MCP CLI Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/justinwongcn/go-mcp-cli/pkg/bridge"
+	"github.com/justinwongcn/go-mcp-cli/pkg/client"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/spf13/cobra"
+)
+
+var (
+	bridgeListen      string
+	bridgeTransport   string
+	bridgeShared      bool
+	bridgeMaxSessions int
+	bridgeIdleTimeout time.Duration
+	bridgeAllowRemote bool
+)
+
+var bridgeCmd = &cobra.Command{
+	Use:   "bridge <server>",
+	Short: "Expose a stdio server over Streamable HTTP or SSE",
+	Long: `Launch a configured stdio server and proxy JSON-RPC traffic between it and
+remote clients connecting over Streamable HTTP or SSE.
+
+By default every remote session gets its own server process. With --shared
+all sessions are multiplexed onto a single server process.
+
+The bridge listens on localhost and rejects requests whose Host or Origin
+header is not localhost, which protects it from DNS rebinding attacks.
+To accept remote clients, listen on another interface and pass
+--allow-remote; the bridge has no authentication of its own.
+
+Streamable HTTP sessions that receive no requests for --idle-timeout are
+closed together with their server process, and at most --max-sessions
+sessions may exist at once.
+
+Examples:
+  # Streamable HTTP on localhost:8080
+  mcp-cli bridge time
+
+  # SSE, one server process shared by all clients
+  mcp-cli bridge time --transport sse --shared
+
+  # Accept clients on all interfaces
+  mcp-cli bridge time --listen :8080 --allow-remote`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		serverName := args[0]
+
+		serverConfig, err := loadServerConfig(serverName)
+		if err != nil {
+			return err
+		}
+		if serverConfig.Transport != "stdio" {
+			return configErrorf("bridge requires a stdio server, %s uses %s", serverName, serverConfig.Transport)
+		}
+
+		// 每个会话各自启动服务器进程，因此每次都创建新的传输
+		b := bridge.New(func(ctx context.Context) (mcp.Connection, error) {
+			transport, err := client.TransportFromConfig(serverConfig)
+			if err != nil {
+				return nil, err
+			}
+			serverLog, err := serverLogWriter(serverName)
+			if err != nil {
+				return nil, err
+			}
+			if command, ok := transport.(*mcp.CommandTransport); ok && serverLog != nil {
+				command.Command.Stderr = serverLog
+			}
+			return transport.Connect(ctx)
+		}, &bridge.Options{
+			Shared:      bridgeShared,
+			MaxSessions: bridgeMaxSessions,
+			IdleTimeout: bridgeIdleTimeout,
+		})
+		defer b.Close()
+
+		var handler http.Handler
+		switch bridgeTransport {
+		case "http":
+			handler = b.StreamableHandler()
+		case "sse":
+			handler = b.SSEHandler()
+		default:
+			return configErrorf("unknown bridge transport: %s (valid: http, sse)", bridgeTransport)
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		mode := "one process per session"
+		if bridgeShared {
+			mode = "shared process"
+		}
+		fmt.Fprintf(os.Stderr, "🔌 Bridging %s over %s (%s)\n", serverName, bridgeTransport, mode)
+		return serveHTTPHandler(ctx, bridgeListen, localOnly(bridgeListen, bridgeAllowRemote, handler))
+	},
+}
+
+func init() {
+	bridgeCmd.Flags().StringVar(&bridgeListen, "listen", "127.0.0.1:8080", "Address to listen on")
+	bridgeCmd.Flags().StringVar(&bridgeTransport, "transport", "http", "Transport to expose (http, sse)")
+	bridgeCmd.Flags().BoolVar(&bridgeShared, "shared", false, "Share one server process across all sessions")
+	bridgeCmd.Flags().BoolVar(&bridgeAllowRemote, "allow-remote", false, "Accept requests whose Host or Origin is not localhost")
+	bridgeCmd.Flags().IntVar(&bridgeMaxSessions, "max-sessions", 100, "Maximum number of concurrent sessions (0 for no limit)")
+	bridgeCmd.Flags().DurationVar(&bridgeIdleTimeout, "idle-timeout", 10*time.Minute, "Close Streamable HTTP sessions idle for this long (0 to keep them open)")
+	rootCmd.AddCommand(bridgeCmd)
+}
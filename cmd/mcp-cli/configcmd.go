@@ -0,0 +1,129 @@
+// Copyright 2025 MCP CLI Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/justinwongcn/go-mcp-cli/pkg/config"
+
+	"github.com/spf13/cobra"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect and validate the configuration",
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check every configured server for mistakes",
+	Long: `Check every configured server without connecting to it:
+
+  - transport is one of stdio, sse, http
+  - stdio servers have a command that can be found on PATH
+  - sse and http servers have a valid http(s) URL
+  - TLS certificate files exist and certFile/keyFile are set together
+  - the working directory exists and timeouts are valid durations
+  - the name field matches the server's key
+  - ${...} references can be resolved
+  - fields that do not apply to the transport are reported as warnings
+
+Unknown fields and duplicate keys are rejected when the config is loaded,
+with the line number of the offending entry. Exits with code 2 when any
+error is found.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cm, err := newConfigManager()
+		if err != nil {
+			return err
+		}
+
+		names := cm.GetServerNames()
+		issues := cm.Validate()
+		errorCount := 0
+		for _, issue := range issues {
+			if issue.Severity == config.SeverityError {
+				errorCount++
+			}
+		}
+
+		switch outputFormat {
+		case outputJSON, outputYAML:
+			if err := printStructured(map[string]any{
+				"valid":   errorCount == 0,
+				"servers": len(names),
+				"issues":  nonNil(issues),
+			}); err != nil {
+				return err
+			}
+
+		case outputTable:
+			rows := make([][]string, 0, len(issues))
+			for _, issue := range issues {
+				rows = append(rows, []string{issue.Server, issue.Severity, issue.Field, issue.Message})
+			}
+			printTable([]string{"SERVER", "SEVERITY", "FIELD", "MESSAGE"}, rows)
+
+		default:
+			byServer := make(map[string][]config.Issue)
+			for _, issue := range issues {
+				byServer[issue.Server] = append(byServer[issue.Server], issue)
+			}
+			for _, name := range names {
+				serverIssues := byServer[name]
+				if len(serverIssues) == 0 {
+					fmt.Printf("✓ %s\n", name)
+					continue
+				}
+				_, path := cm.ServerOrigin(name)
+				fmt.Printf("✗ %s (%s)\n", name, path)
+				for _, issue := range serverIssues {
+					if issue.Field != "" {
+						fmt.Printf("   %s: %s: %s\n", issue.Severity, issue.Field, issue.Message)
+					} else {
+						fmt.Printf("   %s: %s\n", issue.Severity, issue.Message)
+					}
+				}
+			}
+			fmt.Printf("\n%d server(s) checked, %d error(s), %d warning(s)\n", len(names), errorCount, len(issues)-errorCount)
+		}
+
+		if errorCount > 0 {
+			return configErrorf("config has %d error(s)", errorCount)
+		}
+		return nil
+	},
+}
+
+var configSchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the JSON Schema for the go-mcp-cli config format",
+	Long: `Print the JSON Schema for .mcp-cli/config.json. Editors can use it for
+completion and validation by adding this to the config file:
+
+  "$schema": "` + config.SchemaURL + `"`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		_, err := os.Stdout.Write(config.JSONSchema)
+		return err
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configValidateCmd, configSchemaCmd)
+	rootCmd.AddCommand(configCmd)
+}
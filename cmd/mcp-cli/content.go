@@ -0,0 +1,170 @@
+// Copyright 2025 MCP CLI Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// commonExtensions 常见 MIME 类型对应的文件扩展名，
+// 标准库 mime 包在不同系统上的内置表不一致，这里优先使用固定映射
+var commonExtensions = map[string]string{
+	"image/png":        ".png",
+	"image/jpeg":       ".jpg",
+	"image/gif":        ".gif",
+	"image/webp":       ".webp",
+	"image/svg+xml":    ".svg",
+	"image/bmp":        ".bmp",
+	"audio/mpeg":       ".mp3",
+	"audio/mp3":        ".mp3",
+	"audio/wav":        ".wav",
+	"audio/x-wav":      ".wav",
+	"audio/wave":       ".wav",
+	"audio/ogg":        ".ogg",
+	"audio/webm":       ".webm",
+	"audio/flac":       ".flac",
+	"audio/aac":        ".aac",
+	"audio/mp4":        ".m4a",
+	"application/pdf":  ".pdf",
+	"application/json": ".json",
+	"text/plain":       ".txt",
+}
+
+// extensionForMIME 根据 MIME 类型推断文件扩展名
+func extensionForMIME(mimeType string) string {
+	mediaType, _, err := mime.ParseMediaType(mimeType)
+	if err != nil {
+		mediaType = strings.ToLower(strings.TrimSpace(mimeType))
+	}
+	if ext, ok := commonExtensions[mediaType]; ok {
+		return ext
+	}
+	if exts, err := mime.ExtensionsByType(mediaType); err == nil && len(exts) > 0 {
+		return exts[0]
+	}
+	return ".bin"
+}
+
+// saveBlob 将二进制内容保存到 saveDir 下的新文件中，并返回文件路径
+func saveBlob(saveDir, prefix, ext string, data []byte) (string, error) {
+	if saveDir == "" {
+		saveDir = "."
+	}
+	if err := os.MkdirAll(saveDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create save directory: %w", err)
+	}
+
+	// 工具名可能包含路径分隔符等字符，只保留安全字符作为文件名前缀
+	prefix = strings.Map(func(r rune) rune {
+		if r == '-' || r == '_' || r == '.' || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9') {
+			return r
+		}
+		return '_'
+	}, prefix)
+
+	f, err := os.CreateTemp(saveDir, prefix+"-*"+ext)
+	if err != nil {
+		return "", fmt.Errorf("failed to create file: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(data); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", f.Name(), err)
+	}
+	return f.Name(), nil
+}
+
+// renderContent 以人类可读的形式打印一条工具返回内容，二进制内容保存到 saveDir
+func renderContent(content mcp.Content, saveDir, prefix string) error {
+	switch c := content.(type) {
+	case *mcp.TextContent:
+		fmt.Println(c.Text)
+
+	case *mcp.ImageContent:
+		filename, err := saveBlob(saveDir, prefix, extensionForMIME(c.MIMEType), c.Data)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("🖼️  Image (%s, %d bytes) saved to %s\n", c.MIMEType, len(c.Data), filename)
+
+	case *mcp.AudioContent:
+		filename, err := saveBlob(saveDir, prefix, extensionForMIME(c.MIMEType), c.Data)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("🔊 Audio (%s, %d bytes) saved to %s\n", c.MIMEType, len(c.Data), filename)
+
+	case *mcp.EmbeddedResource:
+		if c.Resource == nil {
+			fmt.Println("📄 Embedded resource (empty)")
+			return nil
+		}
+		if c.Resource.Blob != nil {
+			ext := path.Ext(c.Resource.URI)
+			if ext == "" {
+				ext = extensionForMIME(c.Resource.MIMEType)
+			}
+			filename, err := saveBlob(saveDir, prefix, ext, c.Resource.Blob)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("📄 Resource %s (%d bytes) saved to %s\n", c.Resource.URI, len(c.Resource.Blob), filename)
+			return nil
+		}
+		fmt.Printf("📄 Resource %s\n", c.Resource.URI)
+		fmt.Println(c.Resource.Text)
+
+	case *mcp.ResourceLink:
+		label := c.Name
+		if c.Title != "" {
+			label = c.Title
+		}
+		fmt.Printf("🔗 %s: %s", label, c.URI)
+		if c.MIMEType != "" {
+			fmt.Printf(" (%s)", c.MIMEType)
+		}
+		fmt.Println()
+		if c.Description != "" {
+			fmt.Printf("   └─ %s\n", c.Description)
+		}
+
+	default:
+		data, err := json.Marshal(content)
+		if err != nil {
+			return fmt.Errorf("failed to encode content: %w", err)
+		}
+		fmt.Println(string(data))
+	}
+
+	return nil
+}
+
+// renderStructuredContent 以格式化 JSON 打印结构化结果
+func renderStructuredContent(structured any) error {
+	data, err := json.MarshalIndent(structured, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode structured content: %w", err)
+	}
+	fmt.Println("📦 Structured content:")
+	fmt.Println(string(data))
+	return nil
+}
@@ -0,0 +1,321 @@
+// Copyright 2025 MCP CLI Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/justinwongcn/go-mcp-cli/pkg/config"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	setUnset bool
+	setForce bool
+)
+
+var setCmd = &cobra.Command{
+	Use:   "set <server> <field> [value...]",
+	Short: "Change one field of a server configuration",
+	Long: `Change one field of an existing server. Fields use the JSON names from
+the config file, with a dot to address a single env, header or auth key.
+
+List fields (args, auth.scopes) take all remaining values or a JSON array.
+Setting env or headers as a whole takes KEY=VALUE values. Use --unset to
+clear a field or delete a single key.
+
+The result is validated before saving. Errors introduced by the change
+block it unless --force is given.
+
+Examples:
+  mcp-cli set context7 url https://mcp.context7.com/mcp
+  mcp-cli set context7 headers.Authorization 'Bearer ${secret:c7}'
+  mcp-cli set time args -- -y mcp-server-time --local-timezone UTC
+  mcp-cli set time env.TZ --unset`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if setUnset {
+			return cobra.ExactArgs(2)(cmd, args)
+		}
+		return cobra.MinimumNArgs(3)(cmd, args)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name, field := args[0], args[1]
+
+		cm, err := newConfigManager()
+		if err != nil {
+			return err
+		}
+		if !cm.ServerExists(name) {
+			return serverNotFoundError(name)
+		}
+
+		// 在文件锁内修改最新的配置，并发修改其他字段不会丢失
+		var changes []config.FieldChange
+		err = cm.UpdateServer(name, func(server *config.ServerConfig) error {
+			current := server.Clone()
+			var err error
+			if setUnset {
+				err = config.UnsetField(server, field)
+			} else {
+				err = config.SetField(server, field, args[2:])
+			}
+			if err != nil {
+				return &exitError{code: exitGeneralError, err: err}
+			}
+			changes, err = checkEditedServer(name, current, server, setForce)
+			return err
+		})
+		if err != nil {
+			return saveError(err)
+		}
+		return printServerUpdate(name, changes)
+	},
+}
+
+var editCmd = &cobra.Command{
+	Use:   "edit <server>",
+	Short: "Edit a server configuration in $EDITOR",
+	Long: `Open the server's JSON in $VISUAL or $EDITOR (default vi). The edited
+config is checked for unknown fields and validated before it is saved; if
+it is invalid you can re-open the editor to fix it.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+
+		cm, err := newConfigManager()
+		if err != nil {
+			return err
+		}
+		current := cm.GetServer(name)
+		if current == nil {
+			return serverNotFoundError(name)
+		}
+
+		original, err := json.MarshalIndent(current, "", "  ")
+		if err != nil {
+			return err
+		}
+		original = append(original, '\n')
+
+		file, err := os.CreateTemp("", "mcp-cli-"+name+"-*.json")
+		if err != nil {
+			return err
+		}
+		path := file.Name()
+		file.Close()
+		if err := os.WriteFile(path, original, 0600); err != nil {
+			return err
+		}
+
+		for {
+			if err := runEditor(path); err != nil {
+				return fmt.Errorf("editor failed: %w (changes kept in %s)", err, path)
+			}
+			edited, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			if bytes.Equal(bytes.TrimSpace(edited), bytes.TrimSpace(original)) {
+				os.Remove(path)
+				return printServerUpdate(name, nil)
+			}
+
+			var changes []config.FieldChange
+			updated, err := config.ParseServer(edited)
+			if err == nil {
+				changes, err = saveEditedServer(cm, name, current, updated)
+			}
+			if err == nil {
+				os.Remove(path)
+				return printServerUpdate(name, changes)
+			}
+
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			if !confirm("Edit again?") {
+				return configErrorf("edit aborted, changes kept in %s", path)
+			}
+		}
+	},
+}
+
+var renameCmd = &cobra.Command{
+	Use:   "rename <server> <new-name>",
+	Short: "Rename a server",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		oldName, newName := args[0], args[1]
+
+		cm, err := newConfigManager()
+		if err != nil {
+			return err
+		}
+		if !cm.ServerExists(oldName) {
+			return serverNotFoundError(oldName)
+		}
+		if cm.ServerExists(newName) {
+			return configErrorf("server already exists: %s", newName)
+		}
+		if err := cm.RenameServer(oldName, newName); err != nil {
+			return configErrorf("failed to rename server: %w", err)
+		}
+		if isStructuredOutput() {
+			return printStructured(map[string]any{"renamed": oldName, "to": newName})
+		}
+		fmt.Printf("✓ Renamed server: %s → %s\n", oldName, newName)
+		return nil
+	},
+}
+
+var copyCmd = &cobra.Command{
+	Use:   "copy <server> <new-name>",
+	Short: "Copy a server configuration under a new name",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		src, dst := args[0], args[1]
+
+		cm, err := newConfigManager()
+		if err != nil {
+			return err
+		}
+		if !cm.ServerExists(src) {
+			return serverNotFoundError(src)
+		}
+		if cm.ServerExists(dst) {
+			return configErrorf("server already exists: %s", dst)
+		}
+		if err := cm.CopyServer(src, dst); err != nil {
+			return configErrorf("failed to copy server: %w", err)
+		}
+		if isStructuredOutput() {
+			return printStructured(map[string]any{"copied": src, "to": dst})
+		}
+		fmt.Printf("✓ Copied server: %s → %s\n", src, dst)
+		return nil
+	},
+}
+
+func init() {
+	setCmd.Flags().BoolVar(&setUnset, "unset", false, "Clear the field (or delete the env/header key)")
+	setCmd.Flags().BoolVar(&setForce, "force", false, "Save even if validation reports errors")
+	rootCmd.AddCommand(setCmd, editCmd, renameCmd, copyCmd)
+}
+
+// saveEditedServer 将基于 current 编辑得到的 updated 保存并返回字段变化。编辑期间其他进程可能修改了同一服务器，
+// 因此在文件锁内把编辑的字段重放到最新的配置上，而不是整体替换。
+func saveEditedServer(cm *config.ConfigManager, name string, current, updated *config.ServerConfig) ([]config.FieldChange, error) {
+	var changes []config.FieldChange
+	err := cm.UpdateServer(name, func(server *config.ServerConfig) error {
+		latest := server.Clone()
+		rebased, err := config.RebaseServer(current, updated, latest)
+		if err != nil {
+			return err
+		}
+		*server = *rebased
+		changes, err = checkEditedServer(name, latest, server, false)
+		return err
+	})
+	if err != nil {
+		return nil, saveError(err)
+	}
+	return changes, nil
+}
+
+// checkEditedServer 校验修改后的配置，返回逐字段的变化。修改引入了新的错误且未指定 force 时返回错误。
+func checkEditedServer(name string, current, updated *config.ServerConfig, force bool) ([]config.FieldChange, error) {
+	changes := config.DiffServer(current, updated)
+	if len(changes) == 0 {
+		return nil, nil
+	}
+
+	// 只有修改引入的新错误才阻止保存，已有的问题不影响修改其他字段
+	existing := make(map[string]bool)
+	for _, issue := range config.ValidateServerConfig(name, current) {
+		existing[issue.Field+"\x00"+issue.Message] = true
+	}
+	errorCount := 0
+	for _, issue := range config.ValidateServerConfig(name, updated) {
+		if issue.Severity == config.SeverityError && !existing[issue.Field+"\x00"+issue.Message] {
+			errorCount++
+		}
+		if issue.Field != "" {
+			fmt.Fprintf(os.Stderr, "%s: %s: %s\n", issue.Severity, issue.Field, issue.Message)
+		} else {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", issue.Severity, issue.Message)
+		}
+	}
+	if errorCount > 0 && !force {
+		return nil, configErrorf("%s has %d validation error(s), not saved", name, errorCount)
+	}
+	return changes, nil
+}
+
+// saveError 将保存配置时的错误标记为配置错误，已带退出码的错误原样返回
+func saveError(err error) error {
+	var ee *exitError
+	if errors.As(err, &ee) {
+		return err
+	}
+	return configErrorf("failed to save config: %w", err)
+}
+
+// printServerUpdate 按当前输出格式打印 set 和 edit 的结果
+func printServerUpdate(name string, changes []config.FieldChange) error {
+	if isStructuredOutput() {
+		return printStructured(map[string]any{"server": name, "changed": nonNil(changes)})
+	}
+	if len(changes) == 0 {
+		fmt.Println("No changes.")
+		return nil
+	}
+	fmt.Printf("✓ Updated server: %s\n", name)
+	printFieldChanges(changes)
+	return nil
+}
+
+// runEditor 用 $VISUAL 或 $EDITOR 打开文件，编辑器命令可以带参数（如 "code --wait"）
+func runEditor(path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	fields := strings.Fields(editor)
+	cmd := exec.Command(fields[0], append(fields[1:], path)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// confirm 在终端询问是/否，默认为是
+func confirm(prompt string) bool {
+	fmt.Fprintf(os.Stderr, "%s [Y/n] ", prompt)
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return false
+	}
+	answer := strings.ToLower(strings.TrimSpace(line))
+	return answer == "" || answer == "y" || answer == "yes"
+}
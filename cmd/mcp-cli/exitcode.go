@@ -0,0 +1,97 @@
+// Copyright 2025 MCP CLI Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// 退出码，脚本可以据此区分失败原因
+const (
+	exitOK             = 0 // 成功
+	exitGeneralError   = 1 // 通用错误，包括命令行参数和工具参数错误
+	exitConfigError    = 2 // 配置文件无法加载、保存或内容无效
+	exitServerNotFound = 3 // 配置中不存在指定的服务器
+	exitConnectFailure = 4 // 无法连接到服务器
+	exitProtocolError  = 5 // MCP 请求失败（服务器返回错误或连接中断）
+	exitToolError      = 6 // 工具执行返回 isError
+	exitTimeout        = 7 // 操作超时
+)
+
+// exitError 携带退出码的错误
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+// exitCode 返回错误对应的退出码
+func exitCode(err error) int {
+	if err == nil {
+		return exitOK
+	}
+	var ee *exitError
+	if errors.As(err, &ee) {
+		return ee.code
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return exitTimeout
+	}
+	return exitGeneralError
+}
+
+// configErrorf 创建配置错误
+func configErrorf(format string, args ...any) error {
+	return &exitError{code: exitConfigError, err: fmt.Errorf(format, args...)}
+}
+
+// serverNotFoundError 创建服务器不存在错误
+func serverNotFoundError(name string) error {
+	return &exitError{code: exitServerNotFound, err: fmt.Errorf("server not found: %s", name)}
+}
+
+// connectError 标记连接错误，超时单独区分。
+// MCPClient 的 Connect* 方法返回的错误已带有 "failed to connect" 前缀，这里不再重复添加。
+func connectError(err error) error {
+	code := exitConnectFailure
+	if errors.Is(err, context.DeadlineExceeded) {
+		code = exitTimeout
+	}
+	return &exitError{code: code, err: err}
+}
+
+// requestErrorf 包装 MCP 请求错误，超时单独区分
+func requestErrorf(format string, args ...any) error {
+	err := fmt.Errorf(format, args...)
+	code := exitProtocolError
+	if errors.Is(err, context.DeadlineExceeded) {
+		code = exitTimeout
+	}
+	return &exitError{code: code, err: err}
+}
+
+// toolError 表示工具执行返回了 isError
+func toolError(toolName string) error {
+	return &exitError{code: exitToolError, err: fmt.Errorf("tool %s reported an error", toolName)}
+}
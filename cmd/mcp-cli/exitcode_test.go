@@ -0,0 +1,104 @@
+// Copyright 2025 MCP CLI Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/justinwongcn/go-mcp-cli/pkg/auth"
+	"github.com/justinwongcn/go-mcp-cli/pkg/client"
+	"github.com/justinwongcn/go-mcp-cli/pkg/config"
+)
+
+func TestExitCode(t *testing.T) {
+	server := &config.ServerConfig{Name: "s", Transport: "http", URL: "https://x"}
+
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "success", err: nil, want: exitOK},
+		{name: "plain error", err: errors.New("bad flag"), want: exitGeneralError},
+		{name: "config error", err: configErrorf("failed to load config: %w", os.ErrPermission), want: exitConfigError},
+		{name: "server not found", err: serverNotFoundError("s"), want: exitServerNotFound},
+		{name: "wrapped exit error", err: fmt.Errorf("call: %w", serverNotFoundError("s")), want: exitServerNotFound},
+		{name: "connect failure", err: connectionError(server, errors.New("connection refused")), want: exitConnectFailure},
+		{name: "connect timeout", err: connectionError(server, fmt.Errorf("failed to connect: %w", context.DeadlineExceeded)), want: exitTimeout},
+		{name: "not logged in", err: connectionError(server, fmt.Errorf("server s: %w", auth.ErrNotLoggedIn)), want: exitConfigError},
+		{name: "invalid transport config", err: connectionError(server, &client.ConfigError{Server: "s", Err: errors.New("bad tls")}), want: exitConfigError},
+		{name: "request failure", err: requestErrorf("failed to call tool: %w", errors.New("method not found")), want: exitProtocolError},
+		{name: "request timeout", err: requestErrorf("failed to call tool: %w", context.DeadlineExceeded), want: exitTimeout},
+		{name: "tool error", err: toolError("echo"), want: exitToolError},
+		{name: "bare timeout", err: fmt.Errorf("waiting: %w", context.DeadlineExceeded), want: exitTimeout},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exitCode(tt.err); got != tt.want {
+				t.Errorf("exitCode(%v) = %d, want %d", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadServerConfigExitCode(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, "xdg"))
+	t.Setenv(config.ConfigEnv, "")
+	t.Setenv("MCP_CLI_TEST_UNSET", "")
+	os.Unsetenv("MCP_CLI_TEST_UNSET")
+	// 避免读取到当前目录上层的项目配置
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(cwd)
+
+	tests := []struct {
+		name    string
+		content string
+		server  string
+		want    int
+	}{
+		{name: "found", content: `{"servers": {"s": {"transport": "stdio", "command": "x"}}}`, server: "s", want: exitOK},
+		{name: "missing server", content: `{"servers": {"s": {"transport": "stdio", "command": "x"}}}`, server: "other", want: exitServerNotFound},
+		{name: "malformed config", content: `{"servers": `, server: "s", want: exitConfigError},
+		{name: "unresolved variable", content: `{"servers": {"s": {"transport": "stdio", "command": "${MCP_CLI_TEST_UNSET}"}}}`, server: "s", want: exitConfigError},
+	}
+
+	saved := configFile
+	defer func() { configFile = saved }()
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configFile = filepath.Join(dir, fmt.Sprintf("config%d.json", i))
+			if err := os.WriteFile(configFile, []byte(tt.content), 0600); err != nil {
+				t.Fatal(err)
+			}
+			_, err := loadServerConfig(tt.server)
+			if got := exitCode(err); got != tt.want {
+				t.Errorf("loadServerConfig(%q) error = %v, exit code %d, want %d", tt.server, err, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright 2025 MCP CLI Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/justinwongcn/go-mcp-cli/pkg/config"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportFormat          string
+	exportAllowReferences bool
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export [servers...]",
+	Short: "Export server configs in another MCP host's format",
+	Long: `Convert configured servers (all by default) into the config format of
+another MCP host and print it to stdout.
+
+Formats: ` + strings.Join(config.ExportFormats, ", ") + `
+
+Continue configs are printed as YAML and all others as JSON unless
+--output json|yaml is given. Environment variable references are written
+as ${env:VAR} for vscode and cursor; other hosts do not expand them, so
+they are exported verbatim with a warning. Fields the target host cannot
+express (cwd, inheritEnv, auth, tls, timeouts, ...) are left out and
+reported on stderr. Other hosts cannot resolve ${secret:...} and
+${file:...}, so exporting them fails unless --allow-references is given.
+
+Examples:
+  mcp-cli export --format vscode > .vscode/mcp.json
+  mcp-cli export --format cursor time context7
+  mcp-cli export --format continue > .continue/mcpServers/mcp-cli.yaml`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cm, err := newConfigManager()
+		if err != nil {
+			return err
+		}
+
+		servers := cm.ListServers()
+		if len(args) > 0 {
+			servers = make(map[string]*config.ServerConfig, len(args))
+			for _, name := range args {
+				serverConfig := cm.GetServer(name)
+				if serverConfig == nil {
+					return serverNotFoundError(name)
+				}
+				servers[name] = serverConfig
+			}
+		}
+
+		exported, warnings, err := config.Export(servers, exportFormat)
+		if err != nil {
+			return err
+		}
+		if refs := config.LocalReferences(servers, exportFormat); len(refs) > 0 {
+			if !exportAllowReferences {
+				return configErrorf("%s cannot resolve ${secret:...} or ${file:...} references in: %s (use --allow-references to export them anyway)", exportFormat, strings.Join(refs, ", "))
+			}
+			fmt.Fprintf(os.Stderr, "⚠️  Exported config contains references only mcp-cli can resolve: %s\n", strings.Join(refs, ", "))
+		}
+		for _, warning := range warnings {
+			fmt.Fprintf(os.Stderr, "⚠️  %s\n", warning)
+		}
+
+		// 导出的是配置文件内容，未指定结构化格式时使用宿主的原生格式
+		if !isStructuredOutput() {
+			outputFormat = outputJSON
+			if exportFormat == config.FormatContinue {
+				outputFormat = outputYAML
+			}
+		}
+		return printStructured(exported)
+	},
+}
+
+func init() {
+	exportCmd.Flags().StringVarP(&exportFormat, "format", "f", config.FormatGoMCPCLI, "Target format ("+strings.Join(config.ExportFormats, ", ")+")")
+	exportCmd.Flags().BoolVar(&exportAllowReferences, "allow-references", false, "Export ${secret:...} and ${file:...} references that other hosts cannot resolve")
+	rootCmd.AddCommand(exportCmd)
+}
@@ -0,0 +1,66 @@
+// Copyright 2025 MCP CLI Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// localOnly 返回只接受本机 Host 和 Origin 的处理器，allowRemote 为 true 时原样返回 handler。
+//
+// 本地 HTTP 服务没有鉴权，网页可以通过 DNS 重绑定让浏览器把请求发到 127.0.0.1，
+// 这类请求的 Host（以及浏览器附带的 Origin）是攻击者的域名，据此拒绝。
+// 监听地址不是回环地址且未允许远程访问时打印警告，此时其他主机的请求都会被拒绝。
+func localOnly(addr string, allowRemote bool, handler http.Handler) http.Handler {
+	if allowRemote {
+		return handler
+	}
+	if host, _, err := net.SplitHostPort(addr); err == nil && !isLocalHost(host) {
+		fmt.Fprintf(os.Stderr, "⚠️  Listening on %s but only accepting requests addressed to localhost; use --allow-remote to accept other hosts\n", addr)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if !isLocalHost(req.Host) {
+			http.Error(w, "forbidden: host is not localhost (use --allow-remote)", http.StatusForbidden)
+			return
+		}
+		if origin := req.Header.Get("Origin"); origin != "" {
+			u, err := url.Parse(origin)
+			if err != nil || !isLocalHost(u.Host) {
+				http.Error(w, "forbidden: origin is not localhost (use --allow-remote)", http.StatusForbidden)
+				return
+			}
+		}
+		handler.ServeHTTP(w, req)
+	})
+}
+
+// isLocalHost 判断主机名（可带端口）是否为 localhost 或回环地址
+func isLocalHost(host string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
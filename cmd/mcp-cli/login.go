@@ -0,0 +1,216 @@
+// Copyright 2025 MCP CLI Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"runtime"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/justinwongcn/go-mcp-cli/pkg/auth"
+	"github.com/justinwongcn/go-mcp-cli/pkg/client"
+	"github.com/justinwongcn/go-mcp-cli/pkg/config"
+	"github.com/justinwongcn/go-mcp-cli/pkg/secrets"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	loginClientID     string
+	loginClientSecret string
+	loginScopes       []string
+	loginAuthServer   string
+	loginRedirectPort int
+	loginNoBrowser    bool
+	loginLogout       bool
+)
+
+var loginCmd = &cobra.Command{
+	Use:   "login <server>",
+	Short: "Authorize mcp-cli to access an OAuth-protected server",
+	Long: `Run the OAuth 2.1 authorization-code flow (with PKCE and a loopback
+redirect) for an sse or http server and store the resulting tokens.
+
+The authorization server is discovered from the server's protected resource
+metadata. If no client ID is configured, mcp-cli registers itself through
+dynamic client registration. Flags given here are saved to the server's
+"auth" block, and stored tokens are refreshed automatically on later
+connections. A --client-secret is kept in the encrypted secret store and the
+config only references it as ${secret:<server>.clientSecret}.
+
+Examples:
+  mcp-cli login myserver
+  mcp-cli login myserver --client-id my-app --scope read --scope write
+  mcp-cli login myserver --logout`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		serverName := args[0]
+
+		cm, err := newConfigManager()
+		if err != nil {
+			return err
+		}
+		serverConfig := cm.GetServer(serverName)
+		if serverConfig == nil {
+			return serverNotFoundError(serverName)
+		}
+		if serverConfig.Transport != "sse" && serverConfig.Transport != "http" {
+			return configErrorf("login requires an sse or http server, %s uses %s", serverName, serverConfig.Transport)
+		}
+
+		store, err := auth.NewStore("")
+		if err != nil {
+			return err
+		}
+
+		authConfig := &config.AuthConfig{}
+		if serverConfig.Auth != nil {
+			copied := *serverConfig.Auth
+			authConfig = &copied
+		}
+		if cmd.Flags().Changed("client-id") {
+			authConfig.ClientID = loginClientID
+		}
+		if cmd.Flags().Changed("client-secret") {
+			authConfig.ClientSecret = loginClientSecret
+		}
+		if cmd.Flags().Changed("scope") {
+			authConfig.Scopes = loginScopes
+		}
+		if cmd.Flags().Changed("auth-server") {
+			authConfig.AuthorizationServer = loginAuthServer
+		}
+		if cmd.Flags().Changed("redirect-port") {
+			authConfig.RedirectPort = loginRedirectPort
+		}
+
+		// 配置写回未展开的 auth，登录和保存令牌使用展开了变量和 secret 引用的副本
+		pending := serverConfig.Clone()
+		pending.Auth = authConfig
+		resolved, err := config.ResolveServer(pending)
+		if err != nil {
+			return configErrorf("%w", err)
+		}
+		if resolved, err = resolveSecrets(resolved); err != nil {
+			return err
+		}
+
+		if loginLogout {
+			if err := store.Delete(resolved.URL); err != nil {
+				return err
+			}
+			fmt.Printf("✓ Logged out of %s\n", serverName)
+			return nil
+		}
+
+		httpClient, err := client.OAuthHTTPClient(resolved.TLS)
+		if err != nil {
+			return configErrorf("%w", err)
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+		defer cancel()
+
+		creds, err := auth.Login(ctx, resolved.URL, &auth.LoginOptions{
+			ClientID:            resolved.Auth.ClientID,
+			ClientSecret:        resolved.Auth.ClientSecret,
+			Scopes:              resolved.Auth.Scopes,
+			AuthorizationServer: resolved.Auth.AuthorizationServer,
+			RedirectPort:        resolved.Auth.RedirectPort,
+			HTTPClient:          httpClient,
+			OpenURL: func(authURL string) {
+				fmt.Fprintf(os.Stderr, "🔐 Open this URL to authorize mcp-cli:\n\n  %s\n\n", authURL)
+				if !loginNoBrowser {
+					openBrowser(authURL)
+				}
+			},
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := store.Save(resolved.URL, creds); err != nil {
+			return err
+		}
+		// 客户端密钥不以明文写入配置：保存到密钥存储，配置中只保留引用
+		if authConfig.ClientSecret != "" && !secrets.HasReference(authConfig.ClientSecret) {
+			if authConfig.ClientSecret, err = storeClientSecret(serverName, authConfig.ClientSecret); err != nil {
+				return err
+			}
+		}
+		if err := cm.AddServer(serverName, pending); err != nil {
+			return configErrorf("failed to save config: %w", err)
+		}
+
+		fmt.Printf("✓ Logged in to %s\n", serverName)
+		return nil
+	},
+}
+
+func init() {
+	loginCmd.Flags().StringVar(&loginClientID, "client-id", "", "Pre-registered OAuth client ID (default: dynamic client registration)")
+	loginCmd.Flags().StringVar(&loginClientSecret, "client-secret", "", "OAuth client secret for confidential clients (saved to the secret store)")
+	loginCmd.Flags().StringArrayVar(&loginScopes, "scope", nil, "Scope to request (repeatable)")
+	loginCmd.Flags().StringVar(&loginAuthServer, "auth-server", "", "Authorization server URL (default: discovered)")
+	loginCmd.Flags().IntVar(&loginRedirectPort, "redirect-port", 0, "Loopback port for the redirect URI (default: random)")
+	loginCmd.Flags().BoolVar(&loginNoBrowser, "no-browser", false, "Print the authorization URL without opening a browser")
+	loginCmd.Flags().BoolVar(&loginLogout, "logout", false, "Delete stored tokens for the server")
+	rootCmd.AddCommand(loginCmd)
+}
+
+// storeClientSecret 将服务器的 OAuth 客户端密钥保存到密钥存储，返回写入配置的 ${secret:...} 引用
+func storeClientSecret(serverName, value string) (string, error) {
+	// 密钥名只允许字母、数字和 _.-，服务器名中的其他字符替换为 _
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '.', r == '-':
+			return r
+		}
+		return '_'
+	}, serverName) + ".clientSecret"
+
+	store, err := secrets.NewStore("")
+	if err != nil {
+		return "", err
+	}
+	if err := store.Set(name, value); err != nil {
+		return "", fmt.Errorf("failed to save client secret: %w", err)
+	}
+	return "${secret:" + name + "}", nil
+}
+
+// openBrowser 尝试用系统默认浏览器打开 URL，失败时忽略（URL 已打印）
+func openBrowser(url string) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	if err := cmd.Start(); err == nil {
+		go cmd.Wait()
+	}
+}
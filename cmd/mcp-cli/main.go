@@ -16,15 +16,19 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
 	"os"
-	"time"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
 
+	"github.com/justinwongcn/go-mcp-cli/pkg/auth"
 	"github.com/justinwongcn/go-mcp-cli/pkg/client"
 	"github.com/justinwongcn/go-mcp-cli/pkg/config"
 
-	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/spf13/cobra"
 )
 
@@ -32,12 +36,28 @@ var rootCmd = &cobra.Command{
 	Use:   "mcp-cli",
 	Short: "MCP CLI tool for managing Model Context Protocol servers",
 	Long: `A powerful CLI tool for managing and interacting with MCP servers.
-Supports stdio, SSE, and Streamable HTTP transports.`,
+Supports stdio, SSE, and Streamable HTTP transports.
+
+Exit codes:
+  0  success
+  1  general error (invalid flags or tool arguments)
+  2  configuration error
+  3  server not found
+  4  connection failure
+  5  protocol error (MCP request failed)
+  6  tool reported an error (isError)
+  7  timeout`,
+}
+
+var configFile string
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&configFile, "config", "", "Config file to use on top of user and project configs (env: "+config.ConfigEnv+")")
 }
 
 func main() {
 	if err := rootCmd.Execute(); err != nil {
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
 }
 
@@ -49,6 +69,8 @@ var (
 	addHeaders   []string
 	addEnv       []string
 	addRetries   int
+	addTLS       tlsFlags
+	addOptions   connectionFlags
 )
 
 var addCmd = &cobra.Command{
@@ -58,9 +80,9 @@ var addCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		name := args[0]
 
-		cm, err := config.NewConfigManager()
+		cm, err := newConfigManager()
 		if err != nil {
-			return fmt.Errorf("failed to load config: %w", err)
+			return err
 		}
 
 		serverConfig := &config.ServerConfig{
@@ -83,6 +105,7 @@ var addCmd = &cobra.Command{
 			}
 			serverConfig.URL = addURL
 			serverConfig.Headers = parseHeaders(addHeaders)
+			serverConfig.TLS = addTLS.config()
 			if addTransport == "http" {
 				serverConfig.MaxRetries = addRetries
 			}
@@ -90,11 +113,17 @@ var addCmd = &cobra.Command{
 		default:
 			return fmt.Errorf("unknown transport type: %s", addTransport)
 		}
+		if err := addOptions.apply(cmd, serverConfig); err != nil {
+			return err
+		}
 
 		if err := cm.AddServer(name, serverConfig); err != nil {
-			return fmt.Errorf("failed to add server: %w", err)
+			return configErrorf("failed to add server: %w", err)
 		}
 
+		if isStructuredOutput() {
+			return printStructured(map[string]any{"added": name, "transport": addTransport})
+		}
 		fmt.Printf("✓ Added server: %s (%s)\n", name, addTransport)
 		return nil
 	},
@@ -108,26 +137,66 @@ func init() {
 	addCmd.Flags().StringArrayVar(&addHeaders, "header", nil, "Headers for HTTP requests")
 	addCmd.Flags().StringArrayVar(&addEnv, "env", nil, "Environment variables")
 	addCmd.Flags().IntVar(&addRetries, "retries", 3, "Max retries for HTTP transport")
+	addTLS.register(addCmd)
+	addOptions.register(addCmd)
 	rootCmd.AddCommand(addCmd)
 }
 
+var listShowOrigin bool
+
 var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List all configured servers",
-	Run: func(cmd *cobra.Command, args []string) {
-		cm, err := config.NewConfigManager()
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cm, err := newConfigManager()
 		if err != nil {
-			log.Fatalf("Failed to load config: %v", err)
+			return err
 		}
 
 		names := cm.GetServerNames()
+
+		switch outputFormat {
+		case outputJSON, outputYAML:
+			if listShowOrigin {
+				servers := make([]*serverWithOrigin, 0, len(names))
+				for _, name := range names {
+					origin, path := cm.ServerOrigin(name)
+					servers = append(servers, &serverWithOrigin{ServerConfig: cm.GetServer(name), Origin: origin, Path: path})
+				}
+				return printStructured(map[string]any{"servers": servers})
+			}
+			servers := make([]*config.ServerConfig, 0, len(names))
+			for _, name := range names {
+				servers = append(servers, cm.GetServer(name))
+			}
+			return printStructured(map[string]any{"servers": servers})
+
+		case outputTable:
+			headers := []string{"NAME", "TRANSPORT", "TARGET"}
+			if listShowOrigin {
+				headers = append(headers, "ORIGIN")
+			}
+			rows := make([][]string, 0, len(names))
+			for _, name := range names {
+				serverConfig := cm.GetServer(name)
+				row := []string{name, serverConfig.Transport, serverTarget(serverConfig)}
+				if listShowOrigin {
+					origin, path := cm.ServerOrigin(name)
+					row = append(row, fmt.Sprintf("%s (%s)", origin, path))
+				}
+				rows = append(rows, row)
+			}
+			printTable(headers, rows)
+			return nil
+		}
+
 		if len(names) == 0 {
 			fmt.Println("No MCP servers configured.")
 			fmt.Println("Usage:")
 			fmt.Println("  mcp-cli add <name> <transport> [options]")
 			fmt.Println("  mcp-cli add time stdio --command uvx --args mcp-server-time")
 			fmt.Println("  mcp-cli add context7 http --url https://mcp.context7.com/mcp")
-			return
+			return nil
 		}
 
 		fmt.Println("Configured MCP Servers:")
@@ -135,22 +204,29 @@ var listCmd = &cobra.Command{
 			serverConfig := cm.GetServer(name)
 			fmt.Printf("📦 %s (%s)\n", name, serverConfig.Transport)
 			if serverConfig.Transport == "stdio" {
-				args := ""
-				if len(serverConfig.Args) > 0 {
-					for _, arg := range serverConfig.Args {
-						args += " " + arg
-					}
-				}
-				fmt.Printf("   Command: %s%s\n", serverConfig.Command, args)
+				fmt.Printf("   Command: %s\n", serverTarget(serverConfig))
 			} else {
-				fmt.Printf("   URL: %s\n", serverConfig.URL)
+				fmt.Printf("   URL: %s\n", serverTarget(serverConfig))
+			}
+			if listShowOrigin {
+				origin, path := cm.ServerOrigin(name)
+				fmt.Printf("   Origin: %s (%s)\n", origin, path)
 			}
 			fmt.Println()
 		}
+		return nil
 	},
 }
 
+// serverWithOrigin 带来源信息的服务器配置，用于 list --show-origin 的结构化输出
+type serverWithOrigin struct {
+	*config.ServerConfig
+	Origin string `json:"origin"`
+	Path   string `json:"path"`
+}
+
 func init() {
+	listCmd.Flags().BoolVar(&listShowOrigin, "show-origin", false, "Show which config file each server comes from")
 	rootCmd.AddCommand(listCmd)
 }
 
@@ -158,20 +234,26 @@ var removeCmd = &cobra.Command{
 	Use:   "remove <name>",
 	Short: "Remove a server configuration",
 	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		name := args[0]
 
-		cm, err := config.NewConfigManager()
+		cm, err := newConfigManager()
 		if err != nil {
-			log.Fatalf("Failed to load config: %v", err)
+			return err
 		}
 
-		removed := cm.RemoveServer(name)
+		removed, err := cm.RemoveServer(name)
+		if err != nil {
+			return configErrorf("failed to remove server: %w", err)
+		}
 		if !removed {
-			fmt.Printf("❌ Server not found: %s\n", name)
-		} else {
-			fmt.Printf("✓ Removed server: %s\n", name)
+			return serverNotFoundError(name)
 		}
+		if isStructuredOutput() {
+			return printStructured(map[string]any{"removed": name})
+		}
+		fmt.Printf("✓ Removed server: %s\n", name)
+		return nil
 	},
 }
 
@@ -182,132 +264,295 @@ func init() {
 
 var importCmd = &cobra.Command{
 	Use:   "import <config-file>",
-	Short: "Import servers from a Claude Desktop configuration file",
-	Long: `Import MCP servers from a Claude Desktop JSON configuration file.
-Supports both Claude Desktop format (mcpServers) and go-mcp-cli format (servers).
+	Short: "Import servers from another MCP host's configuration file",
+	Long: `Import MCP servers from another MCP host's configuration file.
+The format is detected automatically:
+  - Claude Desktop and Cursor (mcpServers)
+  - VS Code mcp.json (servers) or settings.json (mcp.servers)
+  - Zed settings.json (context_servers)
+  - Continue config.yaml or mcpServers blocks
+  - go-mcp-cli (servers)
+
+Fields mcp-cli does not support are reported as warnings.
+
+Servers that already exist are skipped unless --on-conflict says otherwise:
+  overwrite  replace the existing config
+  rename     import as <name>-2, <name>-3, ...
+  merge      keep existing fields, override with the imported non-empty
+             fields and merge env/headers key by key
 
 Examples:
   # Import from Claude Desktop config
   mcp-cli import ~/.config/claude/claude_desktop_config.json
 
-  # Import from specific file
-  mcp-cli import /path/to/config.json`,
+  # Import from VS Code or Zed
+  mcp-cli import .vscode/mcp.json
+  mcp-cli import ~/.config/zed/settings.json
+
+  # Preview re-syncing two servers from a team config
+  mcp-cli import team.json --on-conflict merge --only github,filesystem --dry-run`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		configPath := args[0]
 
-		importedConfig, err := config.LoadClaudeDesktopConfig(configPath)
+		imported, err := config.LoadHostConfig(configPath)
 		if err != nil {
-			return fmt.Errorf("failed to import config: %w", err)
+			return configErrorf("failed to import config: %w", err)
 		}
 
-		cm, err := config.NewConfigManager()
+		// 结构化输出时不逐条打印，最后一次性输出结果
+		structured := isStructuredOutput()
+		printf := func(format string, a ...any) {
+			if !structured {
+				fmt.Printf(format, a...)
+			}
+		}
+		result := importResult{
+			Format:    imported.Format,
+			DryRun:    importDryRun,
+			Warnings:  nonNil(imported.Warnings),
+			Added:     []importedServer{},
+			Updated:   []importedServer{},
+			Unchanged: []string{},
+			Skipped:   []string{},
+		}
+
+		printf("Detected %s config\n", imported.Format)
+		for _, warning := range imported.Warnings {
+			printf("⚠️  %s\n", warning)
+		}
+
+		cm, err := newConfigManager()
 		if err != nil {
-			return fmt.Errorf("failed to load config manager: %w", err)
+			return err
+		}
+
+		names := make([]string, 0, len(imported.Config.Servers))
+		for name := range imported.Config.Servers {
+			if importSelected(name) {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		for _, pattern := range importOnly {
+			if !slices.ContainsFunc(names, func(name string) bool { return matchName(pattern, name) }) {
+				warning := fmt.Sprintf("--only %s matched no servers", pattern)
+				result.Warnings = append(result.Warnings, warning)
+				printf("⚠️  %s\n", warning)
+			}
+		}
+
+		// 改名时避开已有服务器和本次导入的所有名称
+		taken := make(map[string]bool)
+		for _, name := range cm.GetServerNames() {
+			taken[name] = true
+		}
+		for name := range imported.Config.Servers {
+			taken[name] = true
 		}
 
-		importedCount := 0
-		for name, server := range importedConfig.Servers {
-			if cm.ServerExists(name) {
-				fmt.Printf("⚠️  Server '%s' already exists, skipping\n", name)
+		for _, name := range names {
+			server := imported.Config.Servers[name]
+			target := name
+			existing := cm.GetServer(name)
+			var incoming *config.ServerConfig // 合并时导入的原始配置
+
+			if existing != nil && len(config.DiffServer(existing, server)) == 0 {
+				result.Unchanged = append(result.Unchanged, name)
+				printf("= %s unchanged\n", name)
 				continue
 			}
-			if err := cm.AddServer(name, server); err != nil {
-				return fmt.Errorf("failed to add server '%s': %w", name, err)
+			if existing != nil {
+				switch importOnConflict {
+				case conflictSkip:
+					result.Skipped = append(result.Skipped, name)
+					printf("⚠️  Server '%s' already exists, skipping\n", name)
+					continue
+				case conflictMerge:
+					incoming = server
+					server = config.MergeServer(existing, server)
+				case conflictRename:
+					target = uniqueServerName(name, taken)
+					taken[target] = true
+					renamed := *server
+					renamed.Name = target
+					server = &renamed
+					existing = nil
+				}
+			}
+
+			changes := config.DiffServer(existing, server)
+			if !importDryRun {
+				var err error
+				if incoming != nil {
+					// 在文件锁内与最新的配置合并，不覆盖加载之后其他进程的修改
+					err = cm.UpdateServer(target, func(latest *config.ServerConfig) error {
+						merged := config.MergeServer(latest, incoming)
+						changes = config.DiffServer(latest, merged)
+						*latest = *merged
+						return nil
+					})
+				} else {
+					err = cm.AddServer(target, server)
+				}
+				if err != nil {
+					return configErrorf("failed to add server '%s': %w", target, err)
+				}
+			}
+
+			entry := importedServer{Name: target, Transport: server.Transport}
+			switch {
+			case existing == nil:
+				if target != name {
+					entry.Source = name
+				}
+				if importDryRun {
+					printf("+ %s (%s)\n", target, server.Transport)
+				} else if target != name {
+					printf("✓ Imported server: %s as %s (%s)\n", name, target, server.Transport)
+				} else {
+					printf("✓ Imported server: %s (%s)\n", target, server.Transport)
+				}
+				result.Added = append(result.Added, entry)
+			default:
+				entry.Changes = changes
+				if importDryRun {
+					printf("~ %s\n", name)
+				} else {
+					printf("✓ Updated server: %s\n", name)
+				}
+				result.Updated = append(result.Updated, entry)
+			}
+			if importDryRun && !structured {
+				printFieldChanges(changes)
 			}
-			fmt.Printf("✓ Imported server: %s (%s)\n", name, server.Transport)
-			importedCount++
 		}
 
-		fmt.Printf("\n✅ Successfully imported %d server(s)\n", importedCount)
+		if structured {
+			return printStructured(result)
+		}
+		if importDryRun {
+			fmt.Printf("\nDry run: would import %d, update %d and skip %d server(s)\n", len(result.Added), len(result.Updated), len(result.Skipped))
+			return nil
+		}
+		fmt.Printf("\n✅ Successfully imported %d and updated %d server(s)\n", len(result.Added), len(result.Updated))
 		return nil
 	},
 }
 
-var toolsCmd = &cobra.Command{
-	Use:   "tools <server>",
-	Short: "List available tools for a server",
-	Args:  cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-		defer cancel()
+// importResult import 命令的结构化输出
+type importResult struct {
+	Format    string           `json:"format"`
+	DryRun    bool             `json:"dryRun"`
+	Warnings  []string         `json:"warnings"`
+	Added     []importedServer `json:"added"`
+	Updated   []importedServer `json:"updated"`
+	Unchanged []string         `json:"unchanged"`
+	Skipped   []string         `json:"skipped"`
+}
 
-		serverName := args[0]
+// importedServer 导入或更新的一个服务器
+type importedServer struct {
+	Name      string               `json:"name"`
+	Source    string               `json:"source,omitempty"` // 以 rename 方式导入时的原名
+	Transport string               `json:"transport"`
+	Changes   []config.FieldChange `json:"changes,omitempty"` // 更新已有服务器时的字段变化
+}
 
-		cm, err := config.NewConfigManager()
-		if err != nil {
-			return fmt.Errorf("failed to load config: %w", err)
-		}
+// 导入时同名服务器的处理方式
+const (
+	conflictSkip      = "skip"
+	conflictOverwrite = "overwrite"
+	conflictRename    = "rename"
+	conflictMerge     = "merge"
+)
 
-		serverConfig := cm.GetServer(serverName)
-		if serverConfig == nil {
-			fmt.Printf("❌ Server not found: %s\n", serverName)
+var (
+	importOnConflict string
+	importDryRun     bool
+	importOnly       []string
+	importExclude    []string
+)
+
+func init() {
+	importCmd.Flags().StringVar(&importOnConflict, "on-conflict", conflictSkip, "What to do with servers that already exist (skip, overwrite, rename, merge)")
+	importCmd.Flags().BoolVar(&importDryRun, "dry-run", false, "Show what would be added or changed without saving")
+	importCmd.Flags().StringSliceVar(&importOnly, "only", nil, "Import only these servers (glob patterns, repeatable)")
+	importCmd.Flags().StringSliceVar(&importExclude, "exclude", nil, "Skip these servers (glob patterns, repeatable)")
+	importCmd.PreRunE = func(cmd *cobra.Command, args []string) error {
+		switch importOnConflict {
+		case conflictSkip, conflictOverwrite, conflictRename, conflictMerge:
 			return nil
+		default:
+			return fmt.Errorf("unknown --on-conflict value: %s (valid: skip, overwrite, rename, merge)", importOnConflict)
 		}
+	}
+}
 
-		cli := client.NewClient("mcp-cli", "1.0.0")
-		defer cli.Close()
-
-		// Connect based on transport type
-		switch serverConfig.Transport {
-		case "stdio":
-			stdioConfig := &client.StdioConfig{
-				Command: serverConfig.Command,
-				Args:    serverConfig.Args,
-				Env:     serverConfig.Env,
-			}
-			if err := cli.ConnectStdio(ctx, stdioConfig); err != nil {
-				return fmt.Errorf("failed to connect: %w", err)
-			}
+// importSelected 判断服务器是否通过 --only 和 --exclude 过滤
+func importSelected(name string) bool {
+	if len(importOnly) > 0 && !slices.ContainsFunc(importOnly, func(pattern string) bool { return matchName(pattern, name) }) {
+		return false
+	}
+	return !slices.ContainsFunc(importExclude, func(pattern string) bool { return matchName(pattern, name) })
+}
 
-		case "sse":
-			sseConfig := &client.SSEConfig{
-				Endpoint: serverConfig.URL,
-				Headers:  serverConfig.Headers,
-			}
-			if err := cli.ConnectSSE(ctx, sseConfig); err != nil {
-				return fmt.Errorf("failed to connect: %w", err)
-			}
+// matchName 按 glob 模式匹配服务器名，模式无效时按字面比较
+func matchName(pattern, name string) bool {
+	matched, err := path.Match(pattern, name)
+	return matched || err != nil && pattern == name
+}
 
-		case "http":
-			httpConfig := &client.HTTPConfig{
-				Endpoint:   serverConfig.URL,
-				MaxRetries: serverConfig.MaxRetries,
-				Headers:    serverConfig.Headers,
-			}
-			if err = cli.ConnectHTTP(ctx, httpConfig); err != nil {
-				return fmt.Errorf("failed to connect: %w", err)
-			}
+// uniqueServerName 返回 name-2、name-3 … 中第一个未被占用的名称
+func uniqueServerName(name string, taken map[string]bool) string {
+	for i := 2; ; i++ {
+		candidate := fmt.Sprintf("%s-%d", name, i)
+		if !taken[candidate] {
+			return candidate
+		}
+	}
+}
 
+// printFieldChanges 逐字段打印配置变化
+func printFieldChanges(changes []config.FieldChange) {
+	for _, change := range changes {
+		switch {
+		case change.Old == "":
+			fmt.Printf("    + %s: %s\n", change.Field, change.New)
+		case change.New == "":
+			fmt.Printf("    - %s: %s\n", change.Field, change.Old)
 		default:
-			return fmt.Errorf("unknown transport type: %s", serverConfig.Transport)
+			fmt.Printf("    ~ %s: %s → %s\n", change.Field, change.Old, change.New)
 		}
+	}
+}
 
-		fmt.Printf("\n📋 Tools for %s:\n\n", serverName)
+var toolsCmd = &cobra.Command{
+	Use:   "tools <server>",
+	Short: "List available tools for a server",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
 
-		tools, err := cli.ListTools(ctx)
+		serverName := args[0]
+
+		serverConfig, err := loadServerConfig(serverName)
 		if err != nil {
-			return fmt.Errorf("failed to list tools: %w", err)
+			return err
 		}
 
-		if len(tools.Tools) == 0 {
-			fmt.Println("No tools available.")
-			return nil
+		cli, err := connectServer(ctx, serverConfig)
+		if err != nil {
+			return err
 		}
+		defer cli.Close()
 
-		for i, tool := range tools.Tools {
-			fmt.Printf("%d. %s\n", i+1, tool.Name)
-			if tool.Description != "" {
-				desc := tool.Description
-				if len(desc) > 100 {
-					desc = desc[:100] + "..."
-				}
-				fmt.Printf("   └─ %s\n", desc)
-			}
+		tools, err := cli.ListTools(ctx)
+		if err != nil {
+			return requestErrorf("failed to list tools: %w", err)
 		}
-		fmt.Println()
 
-		return nil
+		return printTools(fmt.Sprintf("Tools for %s", serverName), tools)
 	},
 }
 
@@ -316,7 +561,10 @@ func init() {
 }
 
 var (
-	callArgs []string
+	callArgs     []string
+	callJSON     string
+	callJSONFile string
+	callSaveDir  string
 )
 
 var callCmd = &cobra.Command{
@@ -324,100 +572,125 @@ var callCmd = &cobra.Command{
 	Short: "Call a tool on a server",
 	Args:  cobra.ExactArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-		defer cancel()
+		ctx := context.Background()
 
 		serverName := args[0]
 		toolName := args[1]
 
-		cm, err := config.NewConfigManager()
+		serverConfig, err := loadServerConfig(serverName)
 		if err != nil {
-			return fmt.Errorf("failed to load config: %w", err)
+			return err
 		}
 
-		serverConfig := cm.GetServer(serverName)
-		if serverConfig == nil {
-			fmt.Printf("❌ Server not found: %s\n", serverName)
-			return nil
+		cli, err := connectServer(ctx, serverConfig)
+		if err != nil {
+			return err
 		}
-
-		cli := client.NewClient("mcp-cli", "1.0.0")
 		defer cli.Close()
 
-		// Connect based on transport type
-		switch serverConfig.Transport {
-		case "stdio":
-			stdioConfig := &client.StdioConfig{
-				Command: serverConfig.Command,
-				Args:    serverConfig.Args,
-				Env:     serverConfig.Env,
-			}
-			if err := cli.ConnectStdio(ctx, stdioConfig); err != nil {
-				return fmt.Errorf("failed to connect: %w", err)
-			}
-
-		case "sse":
-			sseConfig := &client.SSEConfig{
-				Endpoint: serverConfig.URL,
-			}
-			if err := cli.ConnectSSE(ctx, sseConfig); err != nil {
-				return fmt.Errorf("failed to connect: %w", err)
-			}
-
-		case "http":
-			httpConfig := &client.HTTPConfig{
-				Endpoint:   serverConfig.URL,
-				MaxRetries: serverConfig.MaxRetries,
-			}
-			if err := cli.ConnectHTTP(ctx, httpConfig); err != nil {
-				return fmt.Errorf("failed to connect: %w", err)
-			}
-
-		default:
-			return fmt.Errorf("unknown transport type: %s", serverConfig.Transport)
+		// Parse arguments against the tool's input schema
+		baseArgs, err := loadJSONArguments(callJSON, callJSONFile)
+		if err != nil {
+			return err
+		}
+		argsMap, err := buildToolArguments(ctx, cli, toolName, baseArgs, callArgs)
+		if err != nil {
+			return err
 		}
 
-		fmt.Printf("\n🔧 Calling %s on %s...\n\n", toolName, serverName)
-
-		// Parse arguments
-		argsMap := make(map[string]any)
-		if len(callArgs) > 0 {
-			for _, arg := range callArgs {
-				parts := parseArg(arg)
-				if len(parts) == 2 {
-					argsMap[parts[0]] = parts[1]
-				}
-			}
+		if !isStructuredOutput() {
+			fmt.Printf("\n🔧 Calling %s on %s...\n\n", toolName, serverName)
 		}
 
 		result, err := cli.CallTool(ctx, toolName, argsMap)
 		if err != nil {
-			return fmt.Errorf("failed to call tool: %w", err)
+			return requestErrorf("failed to call tool: %w", err)
 		}
 
-		// Format the result
-		if result.IsError {
-			fmt.Println("❌ Tool execution failed")
+		if err := printCallResult(result, toolName, callSaveDir); err != nil {
+			return err
 		}
-
-		for _, content := range result.Content {
-			if text, ok := content.(*mcp.TextContent); ok {
-				fmt.Println(text.Text)
-			}
+		if result.IsError {
+			return toolError(toolName)
 		}
-		fmt.Println()
-
 		return nil
 	},
 }
 
 func init() {
-	callCmd.Flags().StringArrayVarP(&callArgs, "arg", "a", nil, "Tool arguments (key=value)")
+	callCmd.Flags().StringArrayVarP(&callArgs, "arg", "a", nil, "Tool arguments (key=value, typed by the tool's input schema)")
+	callCmd.Flags().StringVar(&callJSON, "json", "", "Tool arguments as a JSON object (use - to read from stdin)")
+	callCmd.Flags().StringVar(&callJSONFile, "json-file", "", "File containing tool arguments as a JSON object")
+	callCmd.Flags().StringVar(&callSaveDir, "save-dir", ".", "Directory to save image, audio and binary resource contents")
 	rootCmd.AddCommand(callCmd)
 }
 
 // Helper functions
 
+// serverTarget 返回服务器的启动命令或 URL
+func serverTarget(serverConfig *config.ServerConfig) string {
+	if serverConfig.Transport == "stdio" {
+		return strings.Join(append([]string{serverConfig.Command}, serverConfig.Args...), " ")
+	}
+	return serverConfig.URL
+}
+
+// newConfigManager 按 --config 参数加载配置
+func newConfigManager() (*config.ConfigManager, error) {
+	cm, err := config.NewConfigManager(configFile)
+	if err != nil {
+		return nil, configErrorf("failed to load config: %w", err)
+	}
+	return cm, nil
+}
+
+// loadServerConfig 加载配置并查找指定服务器，返回的配置已展开变量并解析 secret 引用，仅用于连接
+func loadServerConfig(name string) (*config.ServerConfig, error) {
+	cm, err := newConfigManager()
+	if err != nil {
+		return nil, err
+	}
+	return resolveServerConfig(cm, name)
+}
+
+// resolveServerConfig 返回展开变量并解析 secret 引用后的服务器配置
+func resolveServerConfig(cm *config.ConfigManager, name string) (*config.ServerConfig, error) {
+	if cm.GetServer(name) == nil {
+		return nil, serverNotFoundError(name)
+	}
+	serverConfig, err := cm.ResolvedServer(name)
+	if err != nil {
+		return nil, configErrorf("%w", err)
+	}
+	return resolveSecrets(serverConfig)
+}
+
+// connectServer 根据服务器配置建立连接
+func connectServer(ctx context.Context, serverConfig *config.ServerConfig) (*client.MCPClient, error) {
+	opts, err := clientOptions(serverConfig.Name)
+	if err != nil {
+		return nil, err
+	}
+	cli, err := client.ConnectFromConfig(ctx, "mcp-cli", serverConfig, opts)
+	if err != nil {
+		return nil, connectionError(serverConfig, err)
+	}
+	applyLogLevel(ctx, cli, serverConfig.Name)
+	return cli, nil
+}
+
+// connectionError 按失败原因返回对应退出码的错误：配置问题和未登录为配置错误，其余为连接错误
+func connectionError(serverConfig *config.ServerConfig, err error) error {
+	var configErr *client.ConfigError
+	switch {
+	case errors.Is(err, auth.ErrNotLoggedIn):
+		return configErrorf("%s requires authorization, run 'mcp-cli login %s'", serverConfig.Name, serverConfig.Name)
+	case errors.As(err, &configErr):
+		return configErrorf("%w", err)
+	}
+	return connectError(err)
+}
+
 func parseEnvVars(envVars []string) map[string]string {
 	result := make(map[string]string)
 	for _, envVar := range envVars {
@@ -440,6 +713,103 @@ func parseHeaders(headers []string) map[string]string {
 	return result
 }
 
+// tlsFlags sse/http 服务器的 TLS 参数，add 和 exec 共用
+type tlsFlags struct {
+	caFile   string
+	certFile string
+	keyFile  string
+	insecure bool
+}
+
+func (f *tlsFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.caFile, "tls-ca", "", "PEM file with CA certificates to trust (SSE/HTTP transport)")
+	cmd.Flags().StringVar(&f.certFile, "tls-cert", "", "PEM client certificate for mutual TLS")
+	cmd.Flags().StringVar(&f.keyFile, "tls-key", "", "PEM private key for --tls-cert")
+	cmd.Flags().BoolVar(&f.insecure, "tls-insecure", false, "Skip server certificate verification (testing only)")
+}
+
+// config 返回参数对应的 TLS 设置，未指定任何参数时返回 nil。
+// 文件路径转换为绝对路径，保存的配置在其他目录下也能使用。
+func (f *tlsFlags) config() *config.TLSConfig {
+	if *f == (tlsFlags{}) {
+		return nil
+	}
+	abs := func(file string) string {
+		if file == "" {
+			return ""
+		}
+		if p, err := filepath.Abs(file); err == nil {
+			return p
+		}
+		return file
+	}
+	return &config.TLSConfig{
+		CAFile:             abs(f.caFile),
+		CertFile:           abs(f.certFile),
+		KeyFile:            abs(f.keyFile),
+		InsecureSkipVerify: f.insecure,
+	}
+}
+
+// connectionFlags 超时和 stdio 进程参数，add 和 exec 共用
+type connectionFlags struct {
+	connectTimeout string
+	callTimeout    string
+	cwd            string
+	inheritEnv     bool
+	shutdownGrace  string
+}
+
+func (f *connectionFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.connectTimeout, "connect-timeout", "", "Timeout for connecting to the server (default 30s)")
+	cmd.Flags().StringVar(&f.callTimeout, "call-timeout", "", "Timeout for each request such as a tool call (default 30s, 0 for none)")
+	cmd.Flags().StringVar(&f.cwd, "cwd", "", "Working directory for the server process (stdio transport)")
+	cmd.Flags().BoolVar(&f.inheritEnv, "inherit-env", true, "Pass mcp-cli's environment to the server process (stdio transport)")
+	cmd.Flags().StringVar(&f.shutdownGrace, "shutdown-grace", "", "How long to wait for the server process to exit before terminating it (default 5s)")
+}
+
+// apply 将命令行中给出的参数写入服务器配置
+func (f *connectionFlags) apply(cmd *cobra.Command, serverConfig *config.ServerConfig) error {
+	durations := []struct {
+		flag  string
+		value string
+		field *config.Duration
+	}{
+		{"connect-timeout", f.connectTimeout, &serverConfig.ConnectTimeout},
+		{"call-timeout", f.callTimeout, &serverConfig.CallTimeout},
+		{"shutdown-grace", f.shutdownGrace, &serverConfig.ShutdownGrace},
+	}
+	for _, d := range durations {
+		if d.value == "" {
+			continue
+		}
+		if _, err := config.Duration(d.value).Value(0); err != nil {
+			return fmt.Errorf("--%s: %w", d.flag, err)
+		}
+		*d.field = config.Duration(d.value)
+	}
+
+	if serverConfig.Transport != "stdio" {
+		for _, name := range []string{"cwd", "inherit-env", "shutdown-grace"} {
+			if cmd.Flags().Changed(name) {
+				return fmt.Errorf("--%s only applies to the stdio transport", name)
+			}
+		}
+		return nil
+	}
+	if f.cwd != "" {
+		cwd, err := filepath.Abs(f.cwd)
+		if err != nil {
+			return fmt.Errorf("--cwd: %w", err)
+		}
+		serverConfig.Cwd = cwd
+	}
+	if cmd.Flags().Changed("inherit-env") {
+		serverConfig.InheritEnv = &f.inheritEnv
+	}
+	return nil
+}
+
 func parseArg(arg string) []string {
 	for i := 0; i < len(arg); i++ {
 		if arg[i] == '=' {
@@ -458,6 +828,12 @@ var (
 	execRetries  int
 	execList     bool
 	execHeaders  []string
+	execEnv      []string
+	execTLS      tlsFlags
+	execOptions  connectionFlags
+	execJSON     string
+	execJSONFile string
+	execSaveDir  string
 )
 
 var execCmd = &cobra.Command{
@@ -497,8 +873,7 @@ Examples:
 		return nil
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-		defer cancel()
+		ctx := context.Background()
 
 		transportType := args[0]
 		toolName := ""
@@ -506,108 +881,83 @@ Examples:
 			toolName = args[1]
 		}
 
-		cli := client.NewClient("mcp-cli-exec", "1.0.0")
-		defer cli.Close()
-
-		var err error
-
+		serverConfig := &config.ServerConfig{
+			Name:      transportType,
+			Transport: transportType,
+		}
 		switch transportType {
 		case "stdio":
 			if execCommand == "" {
 				return fmt.Errorf("stdio transport requires --command flag")
 			}
-			stdioConfig := &client.StdioConfig{
-				Command: execCommand,
-				Args:    execArgs,
-			}
-			err = cli.ConnectStdio(ctx, stdioConfig)
+			serverConfig.Command = execCommand
+			serverConfig.Args = execArgs
+			serverConfig.Env = parseEnvVars(execEnv)
 
-		case "sse":
-			if execURL == "" {
-				return fmt.Errorf("sse transport requires --url flag")
-			}
-			sseConfig := &client.SSEConfig{
-				Endpoint: execURL,
-				Headers:  parseHeaders(execHeaders),
-			}
-			err = cli.ConnectSSE(ctx, sseConfig)
-
-		case "http":
+		case "sse", "http":
 			if execURL == "" {
-				return fmt.Errorf("http transport requires --url flag")
+				return fmt.Errorf("%s transport requires --url flag", transportType)
 			}
-			httpConfig := &client.HTTPConfig{
-				Endpoint:   execURL,
-				MaxRetries: execRetries,
-				Headers:    parseHeaders(execHeaders),
+			serverConfig.URL = execURL
+			serverConfig.Headers = parseHeaders(execHeaders)
+			serverConfig.TLS = execTLS.config()
+			if transportType == "http" {
+				serverConfig.MaxRetries = execRetries
 			}
-			err = cli.ConnectHTTP(ctx, httpConfig)
 
 		default:
 			return fmt.Errorf("unknown transport type: %s (valid: stdio, sse, http)", transportType)
 		}
+		if err := execOptions.apply(cmd, serverConfig); err != nil {
+			return err
+		}
 
+		opts, err := clientOptions(transportType)
+		if err != nil {
+			return err
+		}
+		cli, err := client.ConnectFromConfig(ctx, "mcp-cli-exec", serverConfig, opts)
 		if err != nil {
-			return fmt.Errorf("failed to connect: %w", err)
+			return connectionError(serverConfig, err)
 		}
+		defer cli.Close()
+		applyLogLevel(ctx, cli, transportType)
 
 		if execList {
-			fmt.Printf("\n📋 Tools for %s server:\n\n", transportType)
-
 			tools, err := cli.ListTools(ctx)
 			if err != nil {
-				return fmt.Errorf("failed to list tools: %w", err)
+				return requestErrorf("failed to list tools: %w", err)
 			}
 
-			if len(tools.Tools) == 0 {
-				fmt.Println("No tools available.")
-				return nil
-			}
-
-			for i, tool := range tools.Tools {
-				fmt.Printf("%d. %s\n", i+1, tool.Name)
-				if tool.Description != "" {
-					desc := tool.Description
-					if len(desc) > 100 {
-						desc = desc[:100] + "..."
-					}
-					fmt.Printf("   └─ %s\n", desc)
-				}
-			}
-			fmt.Println()
-
-			return nil
+			return printTools(fmt.Sprintf("Tools for %s server", transportType), tools)
 		}
 
-		fmt.Printf("🔧 Executing %s on %s server...\n\n", toolName, transportType)
+		// Parse tool arguments against the tool's input schema
+		baseArgs, err := loadJSONArguments(execJSON, execJSONFile)
+		if err != nil {
+			return err
+		}
+		argsMap, err := buildToolArguments(ctx, cli, toolName, baseArgs, execToolArgs)
+		if err != nil {
+			return err
+		}
 
-		// Parse tool arguments
-		argsMap := make(map[string]any)
-		for _, arg := range execToolArgs {
-			parts := parseArg(arg)
-			if len(parts) == 2 {
-				argsMap[parts[0]] = parts[1]
-			}
+		if !isStructuredOutput() {
+			fmt.Printf("🔧 Executing %s on %s server...\n\n", toolName, transportType)
 		}
 
 		// Call the tool
 		result, err := cli.CallTool(ctx, toolName, argsMap)
 		if err != nil {
-			return fmt.Errorf("failed to call tool: %w", err)
+			return requestErrorf("failed to call tool: %w", err)
 		}
 
-		// Format the result
-		if result.IsError {
-			fmt.Println("❌ Tool execution failed")
+		if err := printCallResult(result, toolName, execSaveDir); err != nil {
+			return err
 		}
-
-		for _, content := range result.Content {
-			if text, ok := content.(*mcp.TextContent); ok {
-				fmt.Println(text.Text)
-			}
+		if result.IsError {
+			return toolError(toolName)
 		}
-		fmt.Println()
-
 		return nil
 	},
 }
@@ -616,8 +966,14 @@ func init() {
 	execCmd.Flags().StringVar(&execCommand, "command", "", "Command for stdio transport")
 	execCmd.Flags().StringVar(&execURL, "url", "", "URL for SSE/HTTP transport")
 	execCmd.Flags().StringArrayVar(&execArgs, "args", nil, "Arguments for command (stdio transport)")
-	execCmd.Flags().StringArrayVarP(&execToolArgs, "arg", "a", nil, "Tool arguments (key=value)")
+	execCmd.Flags().StringArrayVarP(&execToolArgs, "arg", "a", nil, "Tool arguments (key=value, typed by the tool's input schema)")
+	execCmd.Flags().StringVar(&execJSON, "json", "", "Tool arguments as a JSON object (use - to read from stdin)")
+	execCmd.Flags().StringVar(&execJSONFile, "json-file", "", "File containing tool arguments as a JSON object")
+	execCmd.Flags().StringVar(&execSaveDir, "save-dir", ".", "Directory to save image, audio and binary resource contents")
 	execCmd.Flags().StringArrayVar(&execHeaders, "header", nil, "Headers for HTTP requests")
+	execCmd.Flags().StringArrayVar(&execEnv, "env", nil, "Environment variables (stdio transport)")
+	execTLS.register(execCmd)
+	execOptions.register(execCmd)
 	execCmd.Flags().IntVar(&execRetries, "retries", 3, "Max retries for HTTP transport")
 	execCmd.Flags().BoolVar(&execList, "list", false, "List available tools without calling a specific tool")
 	rootCmd.AddCommand(execCmd)
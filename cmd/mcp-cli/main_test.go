@@ -0,0 +1,51 @@
+// Copyright 2025 MCP CLI Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/justinwongcn/go-mcp-cli/pkg/config"
+)
+
+func TestServerWithOriginJSON(t *testing.T) {
+	server := &serverWithOrigin{
+		ServerConfig: &config.ServerConfig{Name: "s", Transport: "stdio", Command: "x"},
+		Origin:       config.OriginProject,
+		Path:         "/work/.mcp-cli/config.json",
+	}
+	data, err := json.Marshal(server)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// list --show-origin 的来源字段与服务器配置字段位于同一层
+	var got map[string]any
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{
+		"name":      "s",
+		"transport": "stdio",
+		"command":   "x",
+		"origin":    "project",
+		"path":      "/work/.mcp-cli/config.json",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("serverWithOrigin JSON = %v, want %v", got, want)
+	}
+}
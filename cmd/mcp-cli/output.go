@@ -0,0 +1,167 @@
+// Copyright 2025 MCP CLI Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// 输出格式
+const (
+	outputText  = "text"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+	outputTable = "table"
+)
+
+var (
+	outputFormat string
+)
+
+func init() {
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", outputText, "Output format (text, json, yaml, table)")
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		if err := validateOutputFormat(); err != nil {
+			return err
+		}
+		if err := validateLogLevel(); err != nil {
+			return err
+		}
+		// 参数解析通过后出现的错误与用法无关，不再打印帮助信息
+		cmd.SilenceUsage = true
+		return nil
+	}
+}
+
+// validateOutputFormat 检查 --output 参数是否合法
+func validateOutputFormat() error {
+	switch outputFormat {
+	case outputText, outputJSON, outputYAML, outputTable:
+		return nil
+	default:
+		return fmt.Errorf("unknown output format: %s (valid: text, json, yaml, table)", outputFormat)
+	}
+}
+
+// isStructuredOutput 判断是否输出机器可读格式（JSON/YAML）
+func isStructuredOutput() bool {
+	return outputFormat == outputJSON || outputFormat == outputYAML
+}
+
+// printStructured 以 JSON 或 YAML 格式输出结果。
+// YAML 由 JSON 表示转换而来，字段名与 JSON 输出保持一致。
+func printStructured(v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode output: %w", err)
+	}
+
+	if outputFormat == outputJSON {
+		fmt.Println(string(data))
+		return nil
+	}
+
+	var generic any
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return fmt.Errorf("failed to encode output: %w", err)
+	}
+	enc := yaml.NewEncoder(os.Stdout)
+	enc.SetIndent(2)
+	if err := enc.Encode(generic); err != nil {
+		return fmt.Errorf("failed to encode output: %w", err)
+	}
+	return enc.Close()
+}
+
+// printTable 以对齐的表格形式输出
+func printTable(headers []string, rows [][]string) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(headers, "\t"))
+	for _, row := range rows {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			cells[i] = strings.Join(strings.Fields(cell), " ")
+		}
+		fmt.Fprintln(w, strings.Join(cells, "\t"))
+	}
+	w.Flush()
+}
+
+// printTools 按当前输出格式打印工具列表
+func printTools(title string, tools *mcp.ListToolsResult) error {
+	switch outputFormat {
+	case outputJSON, outputYAML:
+		tools.Tools = nonNil(tools.Tools)
+		return printStructured(tools)
+
+	case outputTable:
+		rows := make([][]string, 0, len(tools.Tools))
+		for _, tool := range tools.Tools {
+			rows = append(rows, []string{tool.Name, truncate(tool.Description, 80)})
+		}
+		printTable([]string{"NAME", "DESCRIPTION"}, rows)
+		return nil
+	}
+
+	fmt.Printf("\n📋 %s:\n\n", title)
+
+	if len(tools.Tools) == 0 {
+		fmt.Println("No tools available.")
+		return nil
+	}
+
+	for i, tool := range tools.Tools {
+		fmt.Printf("%d. %s\n", i+1, tool.Name)
+		if tool.Description != "" {
+			fmt.Printf("   └─ %s\n", truncate(tool.Description, 100))
+		}
+	}
+	fmt.Println()
+
+	return nil
+}
+
+// printCallResult 按当前输出格式打印工具调用结果，图片、音频等二进制内容保存到 saveDir
+func printCallResult(result *mcp.CallToolResult, toolName, saveDir string) error {
+	if isStructuredOutput() {
+		return printStructured(result)
+	}
+
+	if result.IsError {
+		fmt.Println("❌ Tool execution failed")
+	}
+
+	for _, content := range result.Content {
+		if err := renderContent(content, saveDir, toolName); err != nil {
+			return err
+		}
+	}
+	if result.StructuredContent != nil {
+		if err := renderStructuredContent(result.StructuredContent); err != nil {
+			return err
+		}
+	}
+	fmt.Println()
+
+	return nil
+}
@@ -0,0 +1,213 @@
+// Copyright 2025 MCP CLI Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/spf13/cobra"
+)
+
+var promptsCmd = &cobra.Command{
+	Use:   "prompts <server>",
+	Short: "List available prompts for a server",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		serverName := args[0]
+
+		serverConfig, err := loadServerConfig(serverName)
+		if err != nil {
+			return err
+		}
+
+		cli, err := connectServer(ctx, serverConfig)
+		if err != nil {
+			return err
+		}
+		defer cli.Close()
+
+		prompts, err := cli.ListPrompts(ctx)
+		if err != nil {
+			return requestErrorf("failed to list prompts: %w", err)
+		}
+
+		return printPrompts(serverName, prompts)
+	},
+}
+
+var (
+	promptArgs []string
+)
+
+var promptCmd = &cobra.Command{
+	Use:   "prompt <server> <name>",
+	Short: "Render a prompt from a server",
+	Long: `Render a prompt template from a server and print the resulting messages.
+
+Examples:
+  mcp-cli prompt myserver code_review --arg language=go --arg style=strict`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		serverName := args[0]
+		promptName := args[1]
+
+		arguments, err := parsePromptArguments(promptArgs)
+		if err != nil {
+			return err
+		}
+
+		serverConfig, err := loadServerConfig(serverName)
+		if err != nil {
+			return err
+		}
+
+		cli, err := connectServer(ctx, serverConfig)
+		if err != nil {
+			return err
+		}
+		defer cli.Close()
+
+		result, err := cli.GetPrompt(ctx, promptName, arguments)
+		if err != nil {
+			return requestErrorf("failed to get prompt: %w", err)
+		}
+
+		return printPrompt(serverName, promptName, result)
+	},
+}
+
+func init() {
+	promptCmd.Flags().StringArrayVarP(&promptArgs, "arg", "a", nil, "Prompt arguments (key=value)")
+	rootCmd.AddCommand(promptsCmd)
+	rootCmd.AddCommand(promptCmd)
+}
+
+// parsePromptArguments 解析 --arg key=value 形式的提示参数，格式错误的参数直接报错而不是被忽略
+func parsePromptArguments(pairs []string) (map[string]string, error) {
+	arguments := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		parts := parseArg(pair)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("argument %q is not in key=value form", pair)
+		}
+		arguments[parts[0]] = parts[1]
+	}
+	return arguments, nil
+}
+
+// printPrompts 按当前输出格式打印提示模板列表
+func printPrompts(serverName string, prompts *mcp.ListPromptsResult) error {
+	switch outputFormat {
+	case outputJSON, outputYAML:
+		prompts.Prompts = nonNil(prompts.Prompts)
+		return printStructured(prompts)
+
+	case outputTable:
+		rows := make([][]string, 0, len(prompts.Prompts))
+		for _, prompt := range prompts.Prompts {
+			var argNames []string
+			for _, arg := range prompt.Arguments {
+				if arg.Required {
+					argNames = append(argNames, arg.Name+"*")
+				} else {
+					argNames = append(argNames, arg.Name)
+				}
+			}
+			rows = append(rows, []string{prompt.Name, strings.Join(argNames, ","), truncate(prompt.Description, 60)})
+		}
+		printTable([]string{"NAME", "ARGUMENTS", "DESCRIPTION"}, rows)
+		return nil
+	}
+
+	fmt.Printf("\n💬 Prompts for %s:\n\n", serverName)
+
+	if len(prompts.Prompts) == 0 {
+		fmt.Println("No prompts available.")
+		return nil
+	}
+
+	for i, prompt := range prompts.Prompts {
+		fmt.Printf("%d. %s\n", i+1, prompt.Name)
+		if prompt.Description != "" {
+			fmt.Printf("   └─ %s\n", truncate(prompt.Description, 100))
+		}
+		for _, arg := range prompt.Arguments {
+			required := "optional"
+			if arg.Required {
+				required = "required"
+			}
+			fmt.Printf("   • %s (%s)", arg.Name, required)
+			if arg.Description != "" {
+				fmt.Printf(": %s", truncate(arg.Description, 80))
+			}
+			fmt.Println()
+		}
+	}
+	fmt.Println()
+
+	return nil
+}
+
+// printPrompt 按当前输出格式打印渲染后的提示消息
+func printPrompt(serverName, promptName string, result *mcp.GetPromptResult) error {
+	if isStructuredOutput() {
+		return printStructured(result)
+	}
+
+	fmt.Printf("\n💬 Prompt %s on %s\n", promptName, serverName)
+	if result.Description != "" {
+		fmt.Printf("   └─ %s\n", result.Description)
+	}
+	fmt.Println()
+
+	for _, message := range result.Messages {
+		fmt.Printf("[%s]\n", message.Role)
+		fmt.Println(describePromptContent(message.Content))
+		fmt.Println()
+	}
+
+	return nil
+}
+
+// describePromptContent 将提示消息内容转换为可读文本
+func describePromptContent(content mcp.Content) string {
+	switch c := content.(type) {
+	case *mcp.TextContent:
+		return c.Text
+	case *mcp.ImageContent:
+		return fmt.Sprintf("<image %s, %d bytes>", c.MIMEType, len(c.Data))
+	case *mcp.AudioContent:
+		return fmt.Sprintf("<audio %s, %d bytes>", c.MIMEType, len(c.Data))
+	case *mcp.EmbeddedResource:
+		if c.Resource == nil {
+			return "<resource>"
+		}
+		if c.Resource.Blob != nil {
+			return fmt.Sprintf("<resource %s, %d bytes>", c.Resource.URI, len(c.Resource.Blob))
+		}
+		return c.Resource.Text
+	case *mcp.ResourceLink:
+		return fmt.Sprintf("<resource link %s>", c.URI)
+	default:
+		return fmt.Sprintf("<unsupported content %T>", content)
+	}
+}
@@ -0,0 +1,46 @@
+// Copyright 2025 MCP CLI Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParsePromptArguments(t *testing.T) {
+	tests := []struct {
+		name    string
+		pairs   []string
+		want    map[string]string
+		wantErr bool
+	}{
+		{name: "none", want: map[string]string{}},
+		{name: "pairs", pairs: []string{"language=go", "style=a=b", "empty="}, want: map[string]string{"language": "go", "style": "a=b", "empty": ""}},
+		{name: "missing equals", pairs: []string{"language=go", "strict"}, wantErr: true},
+		{name: "empty key", pairs: []string{"=go"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parsePromptArguments(tt.pairs)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parsePromptArguments(%q) error = %v, wantErr %v", tt.pairs, err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parsePromptArguments(%q) = %v, want %v", tt.pairs, got, tt.want)
+			}
+		})
+	}
+}
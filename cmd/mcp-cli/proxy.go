@@ -0,0 +1,100 @@
+// Copyright 2025 MCP CLI Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/justinwongcn/go-mcp-cli/pkg/bridge"
+	"github.com/justinwongcn/go-mcp-cli/pkg/client"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/spf13/cobra"
+)
+
+var proxyStdioCmd = &cobra.Command{
+	Use:   "proxy-stdio <server>",
+	Short: "Expose a remote SSE or HTTP server as a local stdio server",
+	Long: `Speak MCP on stdin/stdout and forward all JSON-RPC traffic to a configured
+sse or http server, including configured headers, notifications and
+server-initiated requests. This lets remote servers be used by hosts that
+only support stdio servers.
+
+Example host configuration:
+  {"command": "mcp-cli", "args": ["proxy-stdio", "context7"]}`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		serverName := args[0]
+
+		serverConfig, err := loadServerConfig(serverName)
+		if err != nil {
+			return err
+		}
+
+		if serverConfig.Transport != "sse" && serverConfig.Transport != "http" {
+			return configErrorf("proxy-stdio requires an sse or http server, %s uses %s", serverName, serverConfig.Transport)
+		}
+		transport, err := client.TransportFromConfig(serverConfig)
+		if err != nil {
+			return connectionError(serverConfig, err)
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		// stdout 用于协议通信，状态信息一律输出到 stderr。
+		// SSE 的事件流绑定到连接上下文，因此使用随信号取消的 ctx 而不是带超时的上下文。
+		var up mcp.Connection
+		if streamable, ok := transport.(*mcp.StreamableClientTransport); ok {
+			up, err = bridge.DialStreamable(ctx, streamable)
+		} else {
+			up, err = transport.Connect(ctx)
+		}
+		if err != nil {
+			return connectError(fmt.Errorf("failed to connect: %w", err))
+		}
+
+		down, err := (&mcp.StdioTransport{}).Connect(ctx)
+		if err != nil {
+			up.Close()
+			return err
+		}
+
+		fmt.Fprintf(os.Stderr, "🔌 Proxying stdio to %s (%s)\n", serverName, serverConfig.URL)
+
+		done := make(chan struct{})
+		go func() {
+			bridge.Pipe(down, up)
+			close(done)
+		}()
+
+		select {
+		case <-done:
+		case <-ctx.Done():
+			down.Close()
+			up.Close()
+			<-done
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(proxyStdioCmd)
+}
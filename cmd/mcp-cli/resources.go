@@ -0,0 +1,256 @@
+// Copyright 2025 MCP CLI Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/spf13/cobra"
+)
+
+var resourcesCmd = &cobra.Command{
+	Use:   "resources <server>",
+	Short: "List available resources and resource templates for a server",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		serverName := args[0]
+
+		serverConfig, err := loadServerConfig(serverName)
+		if err != nil {
+			return err
+		}
+
+		cli, err := connectServer(ctx, serverConfig)
+		if err != nil {
+			return err
+		}
+		defer cli.Close()
+
+		resources, err := cli.ListResources(ctx)
+		if err != nil {
+			return requestErrorf("failed to list resources: %w", err)
+		}
+
+		templates, err := cli.ListResourceTemplates(ctx)
+		if err != nil {
+			return requestErrorf("failed to list resource templates: %w", err)
+		}
+
+		return printResources(serverName, resources, templates)
+	},
+}
+
+var (
+	readOutFile string
+	readForce   bool
+)
+
+var readCmd = &cobra.Command{
+	Use:   "read <server> <uri>",
+	Short: "Read a resource from a server",
+	Long: `Read a resource from a server by URI.
+
+Text contents are printed to stdout. Binary (blob) contents are written to
+the file given by --out-file, or to a file named after the last segment of
+the requested URI in the current directory. Existing files are never
+overwritten unless --force is given. With --output json|yaml the full result
+is printed instead, with blobs base64-encoded.
+
+Examples:
+  mcp-cli read docs file:///README.md
+  mcp-cli read assets file:///logo.png --out-file logo.png`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		serverName := args[0]
+		uri := args[1]
+
+		serverConfig, err := loadServerConfig(serverName)
+		if err != nil {
+			return err
+		}
+
+		cli, err := connectServer(ctx, serverConfig)
+		if err != nil {
+			return err
+		}
+		defer cli.Close()
+
+		result, err := cli.ReadResource(ctx, uri)
+		if err != nil {
+			return requestErrorf("failed to read resource: %w", err)
+		}
+
+		return printReadResult(result, uri, readOutFile, readForce)
+	},
+}
+
+func init() {
+	readCmd.Flags().StringVar(&readOutFile, "out-file", "", "File to write binary resource contents to")
+	readCmd.Flags().BoolVar(&readForce, "force", false, "Overwrite existing files when saving binary contents")
+	rootCmd.AddCommand(resourcesCmd)
+	rootCmd.AddCommand(readCmd)
+}
+
+// printResources 按当前输出格式打印资源和资源模板列表
+func printResources(serverName string, resources *mcp.ListResourcesResult, templates *mcp.ListResourceTemplatesResult) error {
+	switch outputFormat {
+	case outputJSON, outputYAML:
+		return printStructured(map[string]any{
+			"resources":         nonNil(resources.Resources),
+			"resourceTemplates": nonNil(templates.ResourceTemplates),
+		})
+
+	case outputTable:
+		rows := make([][]string, 0, len(resources.Resources)+len(templates.ResourceTemplates))
+		for _, resource := range resources.Resources {
+			rows = append(rows, []string{"resource", resource.Name, resource.URI, resource.MIMEType})
+		}
+		for _, template := range templates.ResourceTemplates {
+			rows = append(rows, []string{"template", template.Name, template.URITemplate, template.MIMEType})
+		}
+		printTable([]string{"KIND", "NAME", "URI", "MIME"}, rows)
+		return nil
+	}
+
+	fmt.Printf("\n📚 Resources for %s:\n\n", serverName)
+	if len(resources.Resources) == 0 {
+		fmt.Println("No resources available.")
+	}
+	for i, resource := range resources.Resources {
+		fmt.Printf("%d. %s\n", i+1, resource.Name)
+		fmt.Printf("   URI: %s\n", resource.URI)
+		if resource.MIMEType != "" {
+			fmt.Printf("   MIME: %s\n", resource.MIMEType)
+		}
+		if resource.Description != "" {
+			fmt.Printf("   └─ %s\n", truncate(resource.Description, 100))
+		}
+	}
+
+	if len(templates.ResourceTemplates) > 0 {
+		fmt.Printf("\n🧩 Resource templates for %s:\n\n", serverName)
+		for i, template := range templates.ResourceTemplates {
+			fmt.Printf("%d. %s\n", i+1, template.Name)
+			fmt.Printf("   URI template: %s\n", template.URITemplate)
+			if template.Description != "" {
+				fmt.Printf("   └─ %s\n", truncate(template.Description, 100))
+			}
+		}
+	}
+	fmt.Println()
+
+	return nil
+}
+
+// printReadResult 按当前输出格式打印资源内容，二进制内容写入文件。
+// 文件名由用户请求的 uri 推导，不使用服务器返回的 URI，避免服务器借此写入任意文件
+func printReadResult(result *mcp.ReadResourceResult, uri, outFile string, force bool) error {
+	if isStructuredOutput() {
+		return printStructured(result)
+	}
+
+	blobCount := 0
+	for _, contents := range result.Contents {
+		if contents.Blob == nil {
+			fmt.Println(contents.Text)
+			continue
+		}
+
+		filename := blobFilename(outFile, uri, blobCount)
+		if err := writeBlobFile(filename, contents.Blob, force); err != nil {
+			return err
+		}
+		blobCount++
+		fmt.Printf("✓ Saved %s (%d bytes) to %s\n", contents.URI, len(contents.Blob), filename)
+	}
+
+	return nil
+}
+
+// blobFilename 计算二进制资源内容的保存路径，未指定 outFile 时取 uri 路径的最后一段
+func blobFilename(outFile, uri string, index int) string {
+	name := outFile
+	if name == "" {
+		if u, err := url.Parse(uri); err == nil {
+			p := u.Path
+			if p == "" {
+				p = u.Opaque
+			}
+			name = path.Base(p)
+		}
+		if name == "" || name == "." || name == "/" || name == ".." {
+			name = "resource.bin"
+		}
+	}
+	if index > 0 {
+		ext := path.Ext(name)
+		name = fmt.Sprintf("%s.%d%s", name[:len(name)-len(ext)], index, ext)
+	}
+	return name
+}
+
+// writeBlobFile 写入二进制内容，force 为 false 时拒绝覆盖已存在的文件
+func writeBlobFile(filename string, data []byte, force bool) error {
+	flags := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	if force {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+	f, err := os.OpenFile(filename, flags, 0644)
+	if errors.Is(err, fs.ErrExist) {
+		return fmt.Errorf("%s already exists (use --force to overwrite or --out-file to choose another file)", filename)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", filename, err)
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write %s: %w", filename, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filename, err)
+	}
+	return nil
+}
+
+// truncate 将过长的描述文本截断为 n 个字符，按字符而不是字节截断，避免切开多字节的 UTF-8 字符
+func truncate(s string, n int) string {
+	count := 0
+	for i := range s {
+		if count == n {
+			return s[:i] + "..."
+		}
+		count++
+	}
+	return s
+}
+
+// nonNil 将 nil 切片转换为空切片，保证 JSON 输出为 [] 而不是 null
+func nonNil[T any](items []T) []T {
+	if items == nil {
+		return []T{}
+	}
+	return items
+}
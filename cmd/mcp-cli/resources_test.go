@@ -0,0 +1,98 @@
+// Copyright 2025 MCP CLI Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestBlobFilename(t *testing.T) {
+	tests := []struct {
+		name    string
+		outFile string
+		uri     string
+		index   int
+		want    string
+	}{
+		{name: "out file", outFile: "x.png", uri: "file:///logo.png", want: "x.png"},
+		{name: "uri basename", uri: "file:///assets/logo.png", want: "logo.png"},
+		{name: "query ignored", uri: "https://host/a/b.bin?x=/etc/passwd", want: "b.bin"},
+		{name: "opaque uri", uri: "urn:thing", want: "thing"},
+		{name: "trailing slash", uri: "file:///dir/", want: "dir"},
+		{name: "root", uri: "file:///", want: "resource.bin"},
+		{name: "parent", uri: "file:///a/..", want: "resource.bin"},
+		{name: "second blob", uri: "file:///logo.png", index: 1, want: "logo.1.png"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := blobFilename(tt.outFile, tt.uri, tt.index); got != tt.want {
+				t.Errorf("blobFilename(%q, %q, %d) = %q, want %q", tt.outFile, tt.uri, tt.index, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteBlobFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "blob.bin")
+	if err := writeBlobFile(path, []byte("one"), false); err != nil {
+		t.Fatal(err)
+	}
+
+	err := writeBlobFile(path, []byte("two"), false)
+	if err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("writeBlobFile() over existing file error = %v, want already exists", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "one" {
+		t.Errorf("existing file was overwritten: %q", data)
+	}
+
+	if err := writeBlobFile(path, []byte("2"), true); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "2" {
+		t.Errorf("file after forced write = %q, want %q", data, "2")
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		s    string
+		n    int
+		want string
+	}{
+		{s: "short", n: 10, want: "short"},
+		{s: "exact", n: 5, want: "exact"},
+		{s: "too long", n: 3, want: "too..."},
+		{s: "读取资源内容", n: 6, want: "读取资源内容"},
+		{s: "读取资源内容", n: 2, want: "读取..."},
+		{s: "héllo wörld", n: 7, want: "héllo w..."},
+		{s: "", n: 0, want: ""},
+	}
+
+	for _, tt := range tests {
+		got := truncate(tt.s, tt.n)
+		if got != tt.want {
+			t.Errorf("truncate(%q, %d) = %q, want %q", tt.s, tt.n, got, tt.want)
+		}
+		if !utf8.ValidString(got) {
+			t.Errorf("truncate(%q, %d) = %q is not valid UTF-8", tt.s, tt.n, got)
+		}
+	}
+}
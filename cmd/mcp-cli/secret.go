@@ -0,0 +1,216 @@
+// Copyright 2025 MCP CLI Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"strings"
+
+	"github.com/justinwongcn/go-mcp-cli/pkg/config"
+	"github.com/justinwongcn/go-mcp-cli/pkg/secrets"
+
+	"github.com/spf13/cobra"
+)
+
+var secretCmd = &cobra.Command{
+	Use:   "secret",
+	Short: "Manage encrypted secrets referenced from server configs",
+	Long: `Manage secrets stored encrypted in the user config directory.
+
+Header, environment, url and auth clientId/clientSecret values in server
+configs can reference secrets as ${secret:name}. References are resolved only when connecting, so the
+secret values never appear in .mcp-cli/config.json.
+
+Secrets are encrypted with a passphrase taken from MCP_CLI_SECRETS_PASSPHRASE,
+or with a randomly generated key file (secrets.key) when it is not set.
+
+Examples:
+  mcp-cli secret set github_token
+  mcp-cli add github -t http --url https://example.com/mcp \
+    --header 'Authorization=Bearer ${secret:github_token}'`,
+}
+
+var secretSetCmd = &cobra.Command{
+	Use:   "set <name> [value]",
+	Short: "Store a secret (reads the value from stdin when omitted)",
+	Args:  cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := secrets.NewStore("")
+		if err != nil {
+			return err
+		}
+
+		value := ""
+		if len(args) == 2 {
+			value = args[1]
+		} else {
+			fmt.Fprintf(os.Stderr, "Enter value for %s: ", args[0])
+			line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+			if err != nil && line == "" {
+				return fmt.Errorf("failed to read secret value: %w", err)
+			}
+			value = strings.TrimRight(line, "\r\n")
+		}
+
+		if err := store.Set(args[0], value); err != nil {
+			return err
+		}
+		fmt.Printf("✓ Secret %s saved\n", args[0])
+		return nil
+	},
+}
+
+var secretGetCmd = &cobra.Command{
+	Use:   "get <name>",
+	Short: "Print a secret value",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := secrets.NewStore("")
+		if err != nil {
+			return err
+		}
+		value, err := store.Get(args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Println(value)
+		return nil
+	},
+}
+
+var secretRmCmd = &cobra.Command{
+	Use:   "rm <name>",
+	Short: "Delete a secret",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := secrets.NewStore("")
+		if err != nil {
+			return err
+		}
+		removed, err := store.Delete(args[0])
+		if err != nil {
+			return err
+		}
+		if !removed {
+			return fmt.Errorf("%w: %s", secrets.ErrNotFound, args[0])
+		}
+		fmt.Printf("✓ Secret %s removed\n", args[0])
+		return nil
+	},
+}
+
+var secretListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List secret names",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := secrets.NewStore("")
+		if err != nil {
+			return err
+		}
+		names, err := store.Names()
+		if err != nil {
+			return err
+		}
+
+		switch outputFormat {
+		case outputJSON, outputYAML:
+			return printStructured(map[string]any{"secrets": nonNil(names)})
+		case outputTable:
+			rows := make([][]string, 0, len(names))
+			for _, name := range names {
+				rows = append(rows, []string{name})
+			}
+			printTable([]string{"NAME"}, rows)
+			return nil
+		}
+
+		if len(names) == 0 {
+			fmt.Println("No secrets stored.")
+			return nil
+		}
+		for _, name := range names {
+			fmt.Println(name)
+		}
+		return nil
+	},
+}
+
+func init() {
+	secretCmd.AddCommand(secretSetCmd, secretGetCmd, secretRmCmd, secretListCmd)
+	rootCmd.AddCommand(secretCmd)
+}
+
+// resolveSecrets 返回解析了 ${secret:name} 引用的配置副本，引用可以出现在 url、headers、env
+// 以及 auth 的 clientId、clientSecret、authorizationServer 中。副本仅用于建立连接，不能写回配置文件。
+func resolveSecrets(serverConfig *config.ServerConfig) (*config.ServerConfig, error) {
+	needed := secrets.HasReference(serverConfig.URL)
+	for _, value := range serverConfig.Headers {
+		needed = needed || secrets.HasReference(value)
+	}
+	for _, value := range serverConfig.Env {
+		needed = needed || secrets.HasReference(value)
+	}
+	if auth := serverConfig.Auth; auth != nil {
+		needed = needed || secrets.HasReference(auth.ClientID) || secrets.HasReference(auth.ClientSecret) ||
+			secrets.HasReference(auth.AuthorizationServer)
+	}
+	if !needed {
+		return serverConfig, nil
+	}
+
+	store, err := secrets.NewStore("")
+	if err != nil {
+		return nil, err
+	}
+	// 遇到第一个错误后不再解析，错误统一在最后处理
+	resolve := func(value string) string {
+		if err != nil {
+			return value
+		}
+		var result string
+		result, err = store.Resolve(value)
+		return result
+	}
+
+	resolved := *serverConfig
+	resolved.URL = resolve(serverConfig.URL)
+	resolved.Headers = maps.Clone(serverConfig.Headers)
+	resolved.Env = maps.Clone(serverConfig.Env)
+	for _, values := range []map[string]string{resolved.Headers, resolved.Env} {
+		for key, value := range values {
+			values[key] = resolve(value)
+		}
+	}
+	if serverConfig.Auth != nil {
+		auth := *serverConfig.Auth
+		auth.ClientID = resolve(auth.ClientID)
+		auth.ClientSecret = resolve(auth.ClientSecret)
+		auth.AuthorizationServer = resolve(auth.AuthorizationServer)
+		resolved.Auth = &auth
+	}
+
+	if errors.Is(err, secrets.ErrNotFound) {
+		return nil, configErrorf("server %s: %v (use 'mcp-cli secret set' to add it)", serverConfig.Name, err)
+	}
+	if err != nil {
+		return nil, configErrorf("failed to resolve secrets for %s: %w", serverConfig.Name, err)
+	}
+	return &resolved, nil
+}
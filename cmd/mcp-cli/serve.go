@@ -0,0 +1,165 @@
+// Copyright 2025 MCP CLI Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/justinwongcn/go-mcp-cli/pkg/gateway"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/spf13/cobra"
+)
+
+var (
+	serveStdio       bool
+	serveHTTP        string
+	serveSeparator   string
+	serveAllowRemote bool
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve [servers...]",
+	Short: "Expose configured servers as a single aggregated MCP server",
+	Long: `Connect to all configured servers (or the ones listed) and expose their
+tools, resources and prompts as one MCP server.
+
+Tool and prompt names are prefixed with the server name, e.g. the tool
+get_current_time on server "time" is exposed as "time__get_current_time".
+Resource URIs are exposed unchanged. When an upstream server announces that
+its tool list changed, the gateway re-lists its tools and notifies clients.
+
+With --http the gateway rejects requests whose Host or Origin header is not
+localhost, which protects it from DNS rebinding attacks. Pass --allow-remote
+to accept remote clients; the gateway has no authentication of its own.
+
+Examples:
+  # Serve all configured servers over stdio
+  mcp-cli serve --stdio
+
+  # Serve two servers over Streamable HTTP
+  mcp-cli serve time context7 --http 127.0.0.1:8080`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if serveStdio == (serveHTTP != "") {
+			return fmt.Errorf("exactly one of --stdio or --http is required")
+		}
+
+		cm, err := newConfigManager()
+		if err != nil {
+			return err
+		}
+
+		names := args
+		if len(names) == 0 {
+			names = cm.GetServerNames()
+		}
+		if len(names) == 0 {
+			return configErrorf("no servers configured")
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		gw := gateway.New("mcp-cli-gateway", "1.0.0", serveSeparator)
+		defer gw.Close()
+		gw.OnWarning(func(upstream, message string) {
+			fmt.Fprintf(os.Stderr, "⚠️  %s: %s\n", upstream, message)
+		})
+
+		// stdio 模式下 stdout 用于协议通信，状态信息一律输出到 stderr
+		connected := 0
+		for _, name := range names {
+			if cm.GetServer(name) == nil {
+				return serverNotFoundError(name)
+			}
+			serverConfig, err := resolveServerConfig(cm, name)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "⚠️  Skipping %s: %v\n", name, err)
+				continue
+			}
+
+			// 上游会话在网关运行期间保持打开，连接不能使用带超时的上下文
+			cli, err := connectServer(context.Background(), serverConfig)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "⚠️  Skipping %s: %v\n", name, err)
+				continue
+			}
+
+			warnings, err := gw.AddUpstream(ctx, name, cli)
+			for _, w := range warnings {
+				fmt.Fprintf(os.Stderr, "⚠️  %s: %s\n", name, w)
+			}
+			if err != nil {
+				cli.Close()
+				fmt.Fprintf(os.Stderr, "⚠️  Skipping %s: %v\n", name, err)
+				continue
+			}
+
+			fmt.Fprintf(os.Stderr, "✓ Connected upstream: %s (%s)\n", name, serverConfig.Transport)
+			connected++
+		}
+		if connected == 0 {
+			return &exitError{code: exitConnectFailure, err: fmt.Errorf("no upstream servers could be connected")}
+		}
+
+		if serveStdio {
+			fmt.Fprintln(os.Stderr, "🚀 Serving on stdio")
+			if err := gw.Server().Run(ctx, &mcp.StdioTransport{}); err != nil && !errors.Is(err, context.Canceled) {
+				return err
+			}
+			return nil
+		}
+
+		handler := mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server {
+			return gw.Server()
+		}, nil)
+		return serveHTTPHandler(ctx, serveHTTP, localOnly(serveHTTP, serveAllowRemote, handler))
+	},
+}
+
+func init() {
+	serveCmd.Flags().BoolVar(&serveStdio, "stdio", false, "Serve over stdin/stdout")
+	serveCmd.Flags().StringVar(&serveHTTP, "http", "", "Serve Streamable HTTP on this address (e.g. 127.0.0.1:8080)")
+	serveCmd.Flags().BoolVar(&serveAllowRemote, "allow-remote", false, "With --http, accept requests whose Host or Origin is not localhost")
+	serveCmd.Flags().StringVar(&serveSeparator, "separator", gateway.DefaultSeparator, "Separator between server name and tool/prompt name")
+	rootCmd.AddCommand(serveCmd)
+}
+
+// serveHTTPHandler 在指定地址提供 HTTP 服务，ctx 结束时优雅关闭
+func serveHTTPHandler(ctx context.Context, addr string, handler http.Handler) error {
+	srv := &http.Server{Addr: addr, Handler: handler}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.ListenAndServe()
+	}()
+	fmt.Fprintf(os.Stderr, "🚀 Serving on http://%s\n", addr)
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return srv.Shutdown(shutdownCtx)
+	}
+}
@@ -0,0 +1,166 @@
+// Copyright 2025 MCP CLI Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/justinwongcn/go-mcp-cli/pkg/client"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+var (
+	serverLogs    bool
+	serverLogFile string
+	logLevel      string
+
+	// 日志文件在第一次使用时打开，由所有服务器共用
+	serverLogOnce sync.Once
+	serverLogOut  *os.File
+	serverLogErr  error
+)
+
+func init() {
+	rootCmd.PersistentFlags().BoolVar(&serverLogs, "server-logs", false, "Stream the stderr of stdio servers to the terminal")
+	rootCmd.PersistentFlags().StringVar(&serverLogFile, "server-log-file", "", "Append the stderr of stdio servers to this file")
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "", "Ask servers to send log messages at this level or above ("+strings.Join(client.LogLevels, ", ")+")")
+}
+
+// validateLogLevel 检查 --log-level 参数是否合法
+func validateLogLevel() error {
+	if logLevel != "" && !slices.Contains(client.LogLevels, logLevel) {
+		return fmt.Errorf("unknown log level: %s (valid: %s)", logLevel, strings.Join(client.LogLevels, ", "))
+	}
+	return nil
+}
+
+// clientOptions 返回连接指定服务器时使用的客户端选项
+func clientOptions(serverName string) (*client.Options, error) {
+	serverLog, err := serverLogWriter(serverName)
+	if err != nil {
+		return nil, err
+	}
+	return &client.Options{
+		ServerLog: serverLog,
+		LogMessageHandler: func(params *mcp.LoggingMessageParams) {
+			printLogMessage(serverName, params)
+		},
+	}, nil
+}
+
+// applyLogLevel 连接后按 --log-level 设置服务器的日志级别，服务器不支持时只打印警告
+func applyLogLevel(ctx context.Context, cli *client.MCPClient, serverName string) {
+	if logLevel == "" {
+		return
+	}
+	if err := cli.SetLogLevel(ctx, logLevel); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  %s: cannot set log level: %v\n", serverName, err)
+	}
+}
+
+// printLogMessage 将服务器发送的日志输出到 stderr，格式为 时间 级别 [服务器] 日志名: 内容。
+// 指定了 --log-level 时，不理会 logging/setLevel 的服务器发来的低级别日志也会被过滤掉。
+func printLogMessage(serverName string, params *mcp.LoggingMessageParams) {
+	level := string(params.Level)
+	if rank := slices.Index(client.LogLevels, level); logLevel != "" && rank >= 0 && rank < slices.Index(client.LogLevels, logLevel) {
+		return
+	}
+
+	message, ok := params.Data.(string)
+	if !ok {
+		data, err := json.Marshal(params.Data)
+		if err != nil {
+			message = fmt.Sprint(params.Data)
+		} else {
+			message = string(data)
+		}
+	}
+
+	source := "[" + serverName + "]"
+	if params.Logger != "" {
+		source += " " + params.Logger
+	}
+	fmt.Fprintf(os.Stderr, "%s %-9s %s: %s\n", time.Now().Format(time.RFC3339), strings.ToUpper(level), source, message)
+}
+
+// serverLogWriter 按 --server-logs 和 --server-log-file 返回接收 stdio 服务器 stderr 的输出，
+// 每行以服务器名开头（日志文件中另加时间戳），都未指定时返回 nil
+func serverLogWriter(serverName string) (io.Writer, error) {
+	var writers []io.Writer
+	if serverLogs {
+		writers = append(writers, &linePrefixWriter{out: os.Stderr, prefix: func() string {
+			return "[" + serverName + "] "
+		}})
+	}
+	if serverLogFile != "" {
+		serverLogOnce.Do(func() {
+			serverLogOut, serverLogErr = os.OpenFile(serverLogFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		})
+		if serverLogErr != nil {
+			return nil, fmt.Errorf("failed to open server log file: %w", serverLogErr)
+		}
+		writers = append(writers, &linePrefixWriter{out: serverLogOut, prefix: func() string {
+			return time.Now().Format(time.RFC3339) + " [" + serverName + "] "
+		}})
+	}
+
+	switch len(writers) {
+	case 0:
+		return nil, nil
+	case 1:
+		return writers[0], nil
+	default:
+		return io.MultiWriter(writers...), nil
+	}
+}
+
+// linePrefixWriter 按行输出，每行加上前缀。
+// 整行一次写出，多个服务器同时输出时行与行之间不会交错。
+type linePrefixWriter struct {
+	out    io.Writer
+	prefix func() string
+
+	mu      sync.Mutex
+	pending []byte // 尚未遇到换行的部分
+}
+
+func (w *linePrefixWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.pending = append(w.pending, p...)
+	for {
+		i := bytes.IndexByte(w.pending, '\n')
+		if i < 0 {
+			break
+		}
+		line := append([]byte(w.prefix()), w.pending[:i+1]...)
+		w.pending = w.pending[i+1:]
+		if _, err := w.out.Write(line); err != nil {
+			return len(p), err
+		}
+	}
+	return len(p), nil
+}
@@ -0,0 +1,391 @@
+// Copyright 2025 MCP CLI Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/justinwongcn/go-mcp-cli/pkg/client"
+	"github.com/justinwongcn/go-mcp-cli/pkg/config"
+
+	"github.com/chzyer/readline"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/spf13/cobra"
+)
+
+// shellHelp shell 命令说明，同时用于 shell 内的 help 命令
+const shellHelp = `Start an interactive shell that keeps a single session open to a
+configured server, so server state is preserved between calls.
+
+Shell commands:
+  tools                        List available tools
+  call <tool> [{json}|k=v ...] Call a tool with JSON or key=value arguments
+  resources                    List resources and resource templates
+  read <uri> [file]            Read a resource, saving binary contents to file
+  prompts                      List prompts
+  prompt <name> [k=v ...]      Render a prompt
+  history                      Show command history
+  help                         Show this help
+  exit, quit                   Leave the shell
+
+Tool names, resource URIs, prompt names and argument keys can be completed
+with Tab.`
+
+var shellCmd = &cobra.Command{
+	Use:   "shell <server>",
+	Short: "Start an interactive shell with a persistent server session",
+	Long:  shellHelp,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		serverName := args[0]
+
+		serverConfig, err := loadServerConfig(serverName)
+		if err != nil {
+			return err
+		}
+
+		// 会话在整个 shell 生命周期内保持打开，连接不能使用带超时的上下文
+		cli, err := connectServer(context.Background(), serverConfig)
+		if err != nil {
+			return err
+		}
+		defer cli.Close()
+
+		sh := &shell{serverName: serverName, cli: cli}
+		return sh.run()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(shellCmd)
+}
+
+// shellCommands shell 支持的命令
+var shellCommands = []string{"tools", "call", "resources", "read", "prompts", "prompt", "history", "help", "exit", "quit"}
+
+// shell 交互式会话
+type shell struct {
+	serverName string
+	cli        *client.MCPClient
+	history    []string
+
+	// 用于补全的缓存
+	tools     []*mcp.Tool
+	resources []string
+	prompts   []*mcp.Prompt
+}
+
+// run 启动 REPL 循环
+func (sh *shell) run() error {
+	rl, err := readline.NewEx(&readline.Config{
+		Prompt:          fmt.Sprintf("%s> ", sh.serverName),
+		HistoryFile:     shellHistoryFile(),
+		AutoComplete:    sh,
+		InterruptPrompt: "^C",
+		EOFPrompt:       "exit",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start shell: %w", err)
+	}
+	defer rl.Close()
+
+	sh.refreshCompletions()
+
+	fmt.Printf("Connected to %s. Type 'help' for commands, 'exit' to quit.\n", sh.serverName)
+
+	for {
+		line, err := rl.Readline()
+		if errors.Is(err, readline.ErrInterrupt) {
+			continue
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		sh.history = append(sh.history, line)
+
+		if line == "exit" || line == "quit" {
+			return nil
+		}
+		if err := sh.execute(line); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		}
+	}
+}
+
+// execute 执行一条 shell 命令
+func (sh *shell) execute(line string) error {
+	name, rest, _ := strings.Cut(line, " ")
+	rest = strings.TrimSpace(rest)
+
+	// 每个请求的超时由服务器配置的 callTimeout 控制
+	ctx := context.Background()
+
+	switch name {
+	case "help":
+		fmt.Println(shellHelp)
+		return nil
+
+	case "history":
+		for i, entry := range sh.history {
+			fmt.Printf("%4d  %s\n", i+1, entry)
+		}
+		return nil
+
+	case "tools":
+		tools, err := sh.cli.ListTools(ctx)
+		if err != nil {
+			return requestErrorf("failed to list tools: %w", err)
+		}
+		sh.tools = tools.Tools
+		return printTools(fmt.Sprintf("Tools for %s", sh.serverName), tools)
+
+	case "call":
+		toolName, argText, _ := strings.Cut(rest, " ")
+		if toolName == "" {
+			return fmt.Errorf("usage: call <tool> [{json}|key=value ...]")
+		}
+		argText = strings.TrimSpace(argText)
+
+		var baseArgs map[string]any
+		var pairs []string
+		if strings.HasPrefix(argText, "{") {
+			var err error
+			if baseArgs, err = loadJSONArguments(argText, ""); err != nil {
+				return err
+			}
+		} else {
+			words, err := splitShellWords(argText)
+			if err != nil {
+				return err
+			}
+			pairs = words
+		}
+
+		argsMap, err := buildToolArguments(ctx, sh.cli, toolName, baseArgs, pairs)
+		if err != nil {
+			return err
+		}
+		result, err := sh.cli.CallTool(ctx, toolName, argsMap)
+		if err != nil {
+			return requestErrorf("failed to call tool: %w", err)
+		}
+		return printCallResult(result, toolName, ".")
+
+	case "resources":
+		resources, err := sh.cli.ListResources(ctx)
+		if err != nil {
+			return requestErrorf("failed to list resources: %w", err)
+		}
+		templates, err := sh.cli.ListResourceTemplates(ctx)
+		if err != nil {
+			return requestErrorf("failed to list resource templates: %w", err)
+		}
+		sh.resources = sh.resources[:0]
+		for _, resource := range resources.Resources {
+			sh.resources = append(sh.resources, resource.URI)
+		}
+		return printResources(sh.serverName, resources, templates)
+
+	case "read":
+		fields := strings.Fields(rest)
+		if len(fields) == 0 || len(fields) > 2 {
+			return fmt.Errorf("usage: read <uri> [file]")
+		}
+		outFile := ""
+		if len(fields) == 2 {
+			outFile = fields[1]
+		}
+		result, err := sh.cli.ReadResource(ctx, fields[0])
+		if err != nil {
+			return requestErrorf("failed to read resource: %w", err)
+		}
+		return printReadResult(result, fields[0], outFile, false)
+
+	case "prompts":
+		prompts, err := sh.cli.ListPrompts(ctx)
+		if err != nil {
+			return requestErrorf("failed to list prompts: %w", err)
+		}
+		sh.prompts = prompts.Prompts
+		return printPrompts(sh.serverName, prompts)
+
+	case "prompt":
+		words, err := splitShellWords(rest)
+		if err != nil {
+			return err
+		}
+		if len(words) == 0 {
+			return fmt.Errorf("usage: prompt <name> [key=value ...]")
+		}
+		arguments, err := parsePromptArguments(words[1:])
+		if err != nil {
+			return err
+		}
+		result, err := sh.cli.GetPrompt(ctx, words[0], arguments)
+		if err != nil {
+			return requestErrorf("failed to get prompt: %w", err)
+		}
+		return printPrompt(sh.serverName, words[0], result)
+
+	default:
+		return fmt.Errorf("unknown command: %s (type 'help' for commands)", name)
+	}
+}
+
+// refreshCompletions 预先加载工具、资源和提示模板，用于 Tab 补全。
+// 服务器可能不支持其中某些功能，加载失败时忽略。
+func (sh *shell) refreshCompletions() {
+	// 每个请求的超时由服务器配置的 callTimeout 控制
+	ctx := context.Background()
+
+	if tools, err := sh.cli.ListTools(ctx); err == nil {
+		sh.tools = tools.Tools
+	}
+	if resources, err := sh.cli.ListResources(ctx); err == nil {
+		for _, resource := range resources.Resources {
+			sh.resources = append(sh.resources, resource.URI)
+		}
+	}
+	if prompts, err := sh.cli.ListPrompts(ctx); err == nil {
+		sh.prompts = prompts.Prompts
+	}
+}
+
+// Do 实现 readline.AutoCompleter 接口
+func (sh *shell) Do(line []rune, pos int) ([][]rune, int) {
+	text := string(line[:pos])
+	words := strings.Fields(text)
+	current := ""
+	if len(words) > 0 && !strings.HasSuffix(text, " ") {
+		current = words[len(words)-1]
+		words = words[:len(words)-1]
+	}
+
+	var candidates []string
+	switch {
+	case len(words) == 0:
+		candidates = shellCommands
+
+	case words[0] == "call" && len(words) == 1:
+		for _, tool := range sh.tools {
+			candidates = append(candidates, tool.Name)
+		}
+
+	case words[0] == "call":
+		for _, tool := range sh.tools {
+			if tool.Name != words[1] {
+				continue
+			}
+			schema, _ := schemaMap(tool.InputSchema)
+			props, _ := schema["properties"].(map[string]any)
+			for _, key := range sortedKeys(props) {
+				candidates = append(candidates, key+"=")
+			}
+		}
+
+	case words[0] == "read" && len(words) == 1:
+		candidates = sh.resources
+
+	case words[0] == "prompt" && len(words) == 1:
+		for _, prompt := range sh.prompts {
+			candidates = append(candidates, prompt.Name)
+		}
+
+	case words[0] == "prompt":
+		for _, prompt := range sh.prompts {
+			if prompt.Name != words[1] {
+				continue
+			}
+			for _, arg := range prompt.Arguments {
+				candidates = append(candidates, arg.Name+"=")
+			}
+		}
+	}
+
+	sort.Strings(candidates)
+	var matches [][]rune
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, current) {
+			matches = append(matches, []rune(candidate[len(current):]))
+		}
+	}
+	return matches, len([]rune(current))
+}
+
+// shellHistoryFile 返回 shell 历史记录文件路径，无法确定时返回空字符串（不保存历史）
+func shellHistoryFile() string {
+	dir, err := config.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "shell_history")
+}
+
+// splitShellWords 按空白拆分参数，支持单引号和双引号包裹含空格的值
+func splitShellWords(s string) ([]string, error) {
+	var words []string
+	var current strings.Builder
+	var quote rune
+	inWord := false
+
+	for _, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t':
+			if inWord {
+				words = append(words, current.String())
+				current.Reset()
+				inWord = false
+			}
+		default:
+			current.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote in %q", s)
+	}
+	if inWord {
+		words = append(words, current.String())
+	}
+	return words, nil
+}
@@ -3,14 +3,18 @@ module github.com/justinwongcn/go-mcp-cli
 go 1.23.0
 
 require (
+	github.com/chzyer/readline v1.5.1
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
 	github.com/modelcontextprotocol/go-sdk v1.2.0
 	github.com/spf13/cobra v1.10.2
 	github.com/spf13/pflag v1.0.9 // indirect
+	golang.org/x/crypto v0.33.0
+	golang.org/x/oauth2 v0.30.0
+	golang.org/x/sys v0.30.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
 	github.com/google/jsonschema-go v0.3.0 // indirect
 	github.com/yosida95/uritemplate/v3 v3.0.2 // indirect
-	golang.org/x/oauth2 v0.30.0 // indirect
 )
@@ -0,0 +1,294 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// Copyright 2025 MCP CLI Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// ProtectedResourceMetadata 受保护资源元数据（RFC 9728）
+type ProtectedResourceMetadata struct {
+	Resource             string   `json:"resource"`
+	AuthorizationServers []string `json:"authorization_servers,omitempty"`
+	ScopesSupported      []string `json:"scopes_supported,omitempty"`
+}
+
+// ServerMetadata 授权服务器元数据（RFC 8414）
+type ServerMetadata struct {
+	Issuer                        string   `json:"issuer"`
+	AuthorizationEndpoint         string   `json:"authorization_endpoint"`
+	TokenEndpoint                 string   `json:"token_endpoint"`
+	RegistrationEndpoint          string   `json:"registration_endpoint,omitempty"`
+	ScopesSupported               []string `json:"scopes_supported,omitempty"`
+	CodeChallengeMethodsSupported []string `json:"code_challenge_methods_supported,omitempty"`
+}
+
+// Discovery 授权发现的结果
+type Discovery struct {
+	Resource string                     // 令牌的目标资源（RFC 8707 resource 参数）
+	PRM      *ProtectedResourceMetadata // 受保护资源元数据，服务器未提供时为 nil
+	Server   *ServerMetadata            // 授权服务器元数据
+}
+
+// Discover 发现 MCP 服务器的授权服务器。
+//
+// 依次尝试：未授权请求返回的 WWW-Authenticate 头中的 resource_metadata、
+// 受保护资源元数据的 well-known 地址；找不到时将服务器源地址视为授权服务器。
+// authServer 非空时跳过受保护资源元数据，直接使用该授权服务器。
+func Discover(ctx context.Context, serverURL, authServer string, httpClient *http.Client) (*Discovery, error) {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	d := &Discovery{Resource: canonicalResource(serverURL)}
+
+	if authServer == "" {
+		prm, err := discoverResourceMetadata(ctx, serverURL, httpClient)
+		if err != nil {
+			return nil, err
+		}
+		d.PRM = prm
+		if prm != nil {
+			if prm.Resource != "" {
+				d.Resource = prm.Resource
+			}
+			if len(prm.AuthorizationServers) > 0 {
+				authServer = prm.AuthorizationServers[0]
+			}
+		}
+	}
+	if authServer == "" {
+		u, err := url.Parse(serverURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid server URL: %w", err)
+		}
+		authServer = u.Scheme + "://" + u.Host
+	}
+
+	meta, err := discoverServerMetadata(ctx, authServer, httpClient)
+	if err != nil {
+		return nil, err
+	}
+	d.Server = meta
+	return d, nil
+}
+
+// discoverResourceMetadata 获取受保护资源元数据，服务器未提供时返回 nil
+func discoverResourceMetadata(ctx context.Context, serverURL string, httpClient *http.Client) (*ProtectedResourceMetadata, error) {
+	u, err := url.Parse(serverURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid server URL: %w", err)
+	}
+
+	var candidates []string
+	if metadataURL := probeResourceMetadataURL(ctx, serverURL, httpClient); metadataURL != "" {
+		candidates = append(candidates, metadataURL)
+	}
+	path := strings.TrimSuffix(u.Path, "/")
+	if path != "" {
+		candidates = append(candidates, u.Scheme+"://"+u.Host+"/.well-known/oauth-protected-resource"+path)
+	}
+	candidates = append(candidates, u.Scheme+"://"+u.Host+"/.well-known/oauth-protected-resource")
+
+	for _, candidate := range candidates {
+		var prm ProtectedResourceMetadata
+		if err := getJSON(ctx, httpClient, candidate, &prm); err == nil {
+			return &prm, nil
+		}
+	}
+	return nil, nil
+}
+
+// probeResourceMetadataURL 发送一个未授权的请求，从 401 响应的 WWW-Authenticate 头中提取 resource_metadata
+func probeResourceMetadataURL(ctx context.Context, serverURL string, httpClient *http.Client) string {
+	body := `{"jsonrpc":"2.0","id":1,"method":"ping"}`
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, serverURL, strings.NewReader(body))
+	if err != nil {
+		return ""
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return ""
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		return ""
+	}
+
+	for _, header := range resp.Header.Values("WWW-Authenticate") {
+		if value := authParam(header, "resource_metadata"); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// authParam 从 WWW-Authenticate 头中取出指定参数的值
+func authParam(header, name string) string {
+	for _, part := range strings.Split(header, ",") {
+		part = strings.TrimSpace(part)
+		// 第一个参数前面带有认证方案，如 `Bearer resource_metadata="..."`
+		if scheme, rest, ok := strings.Cut(part, " "); ok && !strings.Contains(scheme, "=") {
+			part = strings.TrimSpace(rest)
+		}
+		key, value, ok := strings.Cut(part, "=")
+		if ok && strings.EqualFold(strings.TrimSpace(key), name) {
+			return strings.Trim(strings.TrimSpace(value), `"`)
+		}
+	}
+	return ""
+}
+
+// discoverServerMetadata 按 RFC 8414 和 OpenID Connect Discovery 获取授权服务器元数据。
+// 都找不到时按 MCP 2025-03-26 规范回退到授权服务器下的默认端点。
+func discoverServerMetadata(ctx context.Context, issuer string, httpClient *http.Client) (*ServerMetadata, error) {
+	u, err := url.Parse(issuer)
+	if err != nil {
+		return nil, fmt.Errorf("invalid authorization server URL: %w", err)
+	}
+	origin := u.Scheme + "://" + u.Host
+	path := strings.TrimSuffix(u.Path, "/")
+
+	candidates := []string{
+		origin + "/.well-known/oauth-authorization-server" + path,
+		origin + "/.well-known/openid-configuration" + path,
+	}
+	if path != "" {
+		candidates = append(candidates, origin+path+"/.well-known/openid-configuration")
+	}
+
+	for _, candidate := range candidates {
+		var meta ServerMetadata
+		if err := getJSON(ctx, httpClient, candidate, &meta); err != nil {
+			continue
+		}
+		if meta.AuthorizationEndpoint == "" || meta.TokenEndpoint == "" {
+			return nil, fmt.Errorf("authorization server metadata at %s is missing endpoints", candidate)
+		}
+		if len(meta.CodeChallengeMethodsSupported) > 0 && !slices.Contains(meta.CodeChallengeMethodsSupported, "S256") {
+			return nil, fmt.Errorf("authorization server %s does not support PKCE S256", issuer)
+		}
+		return &meta, nil
+	}
+
+	return &ServerMetadata{
+		Issuer:                origin,
+		AuthorizationEndpoint: origin + "/authorize",
+		TokenEndpoint:         origin + "/token",
+		RegistrationEndpoint:  origin + "/register",
+	}, nil
+}
+
+// ClientRegistration 动态客户端注册的结果（RFC 7591）
+type ClientRegistration struct {
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret,omitempty"`
+}
+
+// RegisterClient 通过动态客户端注册获取客户端 ID
+func RegisterClient(ctx context.Context, endpoint, redirectURI string, scopes []string, httpClient *http.Client) (*ClientRegistration, error) {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	metadata := map[string]any{
+		"client_name":                "mcp-cli",
+		"redirect_uris":              []string{redirectURI},
+		"grant_types":                []string{"authorization_code", "refresh_token"},
+		"response_types":             []string{"code"},
+		"token_endpoint_auth_method": "none",
+	}
+	if len(scopes) > 0 {
+		metadata["scope"] = strings.Join(scopes, " ")
+	}
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("client registration failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("client registration failed: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return nil, fmt.Errorf("client registration failed: %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	var reg ClientRegistration
+	if err := json.Unmarshal(body, &reg); err != nil {
+		return nil, fmt.Errorf("invalid client registration response: %w", err)
+	}
+	if reg.ClientID == "" {
+		return nil, fmt.Errorf("client registration response is missing client_id")
+	}
+	return &reg, nil
+}
+
+// getJSON 获取并解析 JSON 文档
+func getJSON(ctx context.Context, httpClient *http.Client, u string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", u, resp.Status)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(v)
+}
+
+// canonicalResource 返回服务器的规范资源标识（去掉查询参数、片段和末尾斜杠）
+func canonicalResource(serverURL string) string {
+	u, err := url.Parse(serverURL)
+	if err != nil {
+		return serverURL
+	}
+	u.RawQuery = ""
+	u.Fragment = ""
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	return strings.TrimSuffix(u.String(), "/")
+}
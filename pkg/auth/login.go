@@ -0,0 +1,180 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"golang.org/x/oauth2"
+)
+
+// Copyright 2025 MCP CLI Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// LoginOptions 登录选项
+type LoginOptions struct {
+	ClientID            string   // 预注册的客户端 ID，为空时使用动态客户端注册
+	ClientSecret        string   // 预注册的客户端密钥（可选）
+	Scopes              []string // 申请的权限范围，为空时使用服务器声明的范围
+	AuthorizationServer string   // 授权服务器地址，为空时自动发现
+	RedirectPort        int      // 本地回调端口，为 0 时随机选择
+
+	// OpenURL 打开授权页面，通常用于启动浏览器
+	OpenURL func(authURL string)
+
+	HTTPClient *http.Client // 发现、注册和换取令牌使用的 HTTP 客户端（可选）
+}
+
+// Login 执行 OAuth 2.1 授权码流程（PKCE + 本地回调），返回获得的凭据
+func Login(ctx context.Context, serverURL string, opts *LoginOptions) (*Credentials, error) {
+	httpClient := opts.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", opts.RedirectPort))
+	if err != nil {
+		return nil, fmt.Errorf("failed to start callback listener: %w", err)
+	}
+	defer listener.Close()
+	redirectURI := fmt.Sprintf("http://%s/callback", listener.Addr().String())
+
+	discovery, err := Discover(ctx, serverURL, opts.AuthorizationServer, httpClient)
+	if err != nil {
+		return nil, fmt.Errorf("authorization discovery failed: %w", err)
+	}
+
+	scopes := opts.Scopes
+	if len(scopes) == 0 && discovery.PRM != nil {
+		scopes = discovery.PRM.ScopesSupported
+	}
+
+	clientID, clientSecret := opts.ClientID, opts.ClientSecret
+	if clientID == "" {
+		if discovery.Server.RegistrationEndpoint == "" {
+			return nil, fmt.Errorf("authorization server does not support dynamic client registration, configure a client ID")
+		}
+		reg, err := RegisterClient(ctx, discovery.Server.RegistrationEndpoint, redirectURI, scopes, httpClient)
+		if err != nil {
+			return nil, err
+		}
+		clientID, clientSecret = reg.ClientID, reg.ClientSecret
+	}
+
+	cfg := &oauth2.Config{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  discovery.Server.AuthorizationEndpoint,
+			TokenURL: discovery.Server.TokenEndpoint,
+		},
+		RedirectURL: redirectURI,
+		Scopes:      scopes,
+	}
+
+	verifier := oauth2.GenerateVerifier()
+	state := randomString()
+	resource := oauth2.SetAuthURLParam("resource", discovery.Resource)
+	authURL := cfg.AuthCodeURL(state, oauth2.S256ChallengeOption(verifier), resource)
+
+	code, err := waitForCode(ctx, listener, state, func() {
+		if opts.OpenURL != nil {
+			opts.OpenURL(authURL)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	exchangeCtx := context.WithValue(ctx, oauth2.HTTPClient, httpClient)
+	token, err := cfg.Exchange(exchangeCtx, code, oauth2.VerifierOption(verifier), resource)
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange authorization code: %w", err)
+	}
+
+	return &Credentials{
+		ClientID:      clientID,
+		ClientSecret:  clientSecret,
+		TokenEndpoint: discovery.Server.TokenEndpoint,
+		Resource:      discovery.Resource,
+		Token:         token,
+	}, nil
+}
+
+// callbackResult 回调请求携带的授权结果
+type callbackResult struct {
+	code string
+	err  error
+}
+
+// waitForCode 在本地回调地址上等待授权服务器重定向，返回授权码。
+// 只有带正确 state 的回调会结束等待。
+func waitForCode(ctx context.Context, listener net.Listener, state string, ready func()) (string, error) {
+	results := make(chan callbackResult, 1)
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/callback" {
+			http.NotFound(w, req)
+			return
+		}
+
+		// state 不匹配的请求不是本次授权的重定向（可能是其他网页伪造的），拒绝后继续等待
+		query := req.URL.Query()
+		if query.Get("state") != state {
+			http.Error(w, "authorization callback state mismatch", http.StatusBadRequest)
+			return
+		}
+
+		var result callbackResult
+		switch {
+		case query.Get("error") != "":
+			result.err = fmt.Errorf("authorization failed: %s", strings.TrimSpace(query.Get("error")+" "+query.Get("error_description")))
+		case query.Get("code") == "":
+			result.err = fmt.Errorf("authorization callback is missing the code")
+		default:
+			result.code = query.Get("code")
+		}
+
+		if result.err != nil {
+			http.Error(w, result.err.Error(), http.StatusBadRequest)
+		} else {
+			fmt.Fprintln(w, "Login successful. You can close this window and return to mcp-cli.")
+		}
+		select {
+		case results <- result:
+		default:
+		}
+	})}
+	go srv.Serve(listener)
+	defer srv.Close()
+
+	ready()
+
+	select {
+	case <-ctx.Done():
+		return "", fmt.Errorf("login cancelled: %w", ctx.Err())
+	case result := <-results:
+		return result.code, result.err
+	}
+}
+
+// randomString 生成随机的 state 参数
+func randomString() string {
+	buf := make([]byte, 16)
+	rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
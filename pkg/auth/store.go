@@ -0,0 +1,328 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/justinwongcn/go-mcp-cli/pkg/config"
+	"github.com/justinwongcn/go-mcp-cli/pkg/fileutil"
+	"github.com/justinwongcn/go-mcp-cli/pkg/secrets"
+
+	"golang.org/x/oauth2"
+)
+
+// Copyright 2025 MCP CLI Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// ErrNotLoggedIn 没有保存的令牌
+var ErrNotLoggedIn = errors.New("not logged in")
+
+// Credentials 一个服务器的 OAuth 凭据。
+// 存储中的 ClientSecret 是指向密钥存储的 ${secret:...} 引用，不保存明文。
+type Credentials struct {
+	ClientID      string        `json:"clientId"`
+	ClientSecret  string        `json:"clientSecret,omitempty"`
+	TokenEndpoint string        `json:"tokenEndpoint"`
+	Resource      string        `json:"resource,omitempty"`
+	Token         *oauth2.Token `json:"token"`
+}
+
+// Store 令牌存储，按服务器 URL 保存凭据。
+// 修改在文件锁内读取最新内容后进行，多个 mcp-cli 进程同时刷新令牌不会互相覆盖。
+// 客户端密钥保存在同目录的加密密钥存储中。
+type Store struct {
+	path    string
+	secrets *secrets.Store
+	mu      sync.Mutex
+}
+
+// NewStore 创建令牌存储，path 为空时使用用户配置目录下的 mcp-cli/tokens.json
+func NewStore(path string) (*Store, error) {
+	if path == "" {
+		dir, err := config.UserConfigDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to locate user config directory: %w", err)
+		}
+		path = filepath.Join(dir, "tokens.json")
+	}
+	secretStore, err := secrets.NewStore(filepath.Dir(path))
+	if err != nil {
+		return nil, err
+	}
+	return &Store{path: path, secrets: secretStore}, nil
+}
+
+// Load 读取服务器的凭据，没有时返回 ErrNotLoggedIn
+func (s *Store) Load(serverURL string) (*Credentials, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	all, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	creds, ok := all[canonicalResource(serverURL)]
+	if !ok || creds.Token == nil {
+		return nil, ErrNotLoggedIn
+	}
+	return creds, nil
+}
+
+// Save 保存服务器的凭据，明文的客户端密钥转存到密钥存储
+func (s *Store) Save(serverURL string, creds *Credentials) error {
+	if creds.ClientSecret != "" && !secrets.HasReference(creds.ClientSecret) {
+		name := clientSecretName(serverURL)
+		if err := s.secrets.Set(name, creds.ClientSecret); err != nil {
+			return fmt.Errorf("failed to save client secret: %w", err)
+		}
+		copied := *creds
+		copied.ClientSecret = "${secret:" + name + "}"
+		creds = &copied
+	}
+	return s.update(func(all map[string]*Credentials) {
+		all[canonicalResource(serverURL)] = creds
+	})
+}
+
+// Delete 删除服务器的凭据及其客户端密钥
+func (s *Store) Delete(serverURL string) error {
+	if err := s.update(func(all map[string]*Credentials) {
+		delete(all, canonicalResource(serverURL))
+	}); err != nil {
+		return err
+	}
+	if _, err := s.secrets.Delete(clientSecretName(serverURL)); err != nil {
+		return fmt.Errorf("failed to delete client secret: %w", err)
+	}
+	return nil
+}
+
+// clientSecretName 服务器的客户端密钥在密钥存储中的名称
+func clientSecretName(serverURL string) string {
+	sum := sha256.Sum256([]byte(canonicalResource(serverURL)))
+	return "oauth." + hex.EncodeToString(sum[:8]) + ".clientSecret"
+}
+
+// update 在文件锁内重新读取凭据、应用修改并原子写回
+func (s *Store) update(mutate func(all map[string]*Credentials)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("failed to create token store directory: %w", err)
+	}
+	release, err := fileutil.Lock(s.path + ".lock")
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	all, err := s.read()
+	if err != nil {
+		return err
+	}
+	mutate(all)
+	return s.write(all)
+}
+
+func (s *Store) read() (map[string]*Credentials, error) {
+	all := make(map[string]*Credentials)
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return all, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token store: %w", err)
+	}
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, fmt.Errorf("failed to parse token store %s: %w", s.path, err)
+	}
+	return all, nil
+}
+
+func (s *Store) write(all map[string]*Credentials) error {
+	data, err := json.MarshalIndent(all, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode token store: %w", err)
+	}
+	// 令牌属于敏感信息，仅当前用户可读写
+	if err := fileutil.WriteAtomic(s.path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write token store: %w", err)
+	}
+	return nil
+}
+
+// TokenSource 返回服务器的令牌来源，访问令牌过期时自动刷新并保存新令牌。
+// httpClient 用于刷新请求，应使用与服务器连接相同的 TLS 设置，为 nil 时使用 http.DefaultClient。
+func (s *Store) TokenSource(serverURL string, httpClient *http.Client) (oauth2.TokenSource, error) {
+	creds, err := s.Load(serverURL)
+	if err != nil {
+		return nil, err
+	}
+	clientSecret, err := s.secrets.Resolve(creds.ClientSecret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load client secret: %w", err)
+	}
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	r := &refresher{
+		httpClient:    httpClient,
+		clientID:      creds.ClientID,
+		clientSecret:  clientSecret,
+		tokenEndpoint: creds.TokenEndpoint,
+		resource:      creds.Resource,
+		refreshToken:  creds.Token.RefreshToken,
+	}
+	return &persistingTokenSource{
+		src:       oauth2.ReuseTokenSource(creds.Token, r),
+		store:     s,
+		serverURL: serverURL,
+		creds:     creds,
+	}, nil
+}
+
+// refresher 用刷新令牌换取新的访问令牌。
+// oauth2.Config 的刷新请求无法附加参数，这里自行发送请求以带上 RFC 8707 resource 参数，
+// 使新令牌的受众仍限定为该 MCP 服务器。调用方（oauth2.ReuseTokenSource）保证串行调用。
+type refresher struct {
+	httpClient    *http.Client
+	clientID      string
+	clientSecret  string
+	tokenEndpoint string
+	resource      string
+	refreshToken  string
+}
+
+// tokenResponse 令牌端点的响应
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	TokenType        string `json:"token_type"`
+	RefreshToken     string `json:"refresh_token"`
+	ExpiresIn        int64  `json:"expires_in"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+func (r *refresher) Token() (*oauth2.Token, error) {
+	if r.refreshToken == "" {
+		return nil, errors.New("access token expired and no refresh token is available")
+	}
+
+	form := url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {r.refreshToken},
+	}
+	if r.resource != "" {
+		form.Set("resource", r.resource)
+	}
+	if r.clientSecret == "" {
+		form.Set("client_id", r.clientID)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.tokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if r.clientSecret != "" {
+		// RFC 6749 2.3.1：client_secret_basic 的凭据先按表单编码
+		req.SetBasicAuth(url.QueryEscape(r.clientID), url.QueryEscape(r.clientSecret))
+	}
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+
+	var tr tokenResponse
+	jsonErr := json.Unmarshal(body, &tr)
+	if tr.Error != "" {
+		return nil, fmt.Errorf("token endpoint returned %s", strings.TrimSpace(tr.Error+" "+tr.ErrorDescription))
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("token endpoint returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	if jsonErr != nil {
+		return nil, fmt.Errorf("invalid token response: %w", jsonErr)
+	}
+	if tr.AccessToken == "" {
+		return nil, errors.New("token response is missing access_token")
+	}
+
+	token := &oauth2.Token{
+		AccessToken:  tr.AccessToken,
+		TokenType:    tr.TokenType,
+		RefreshToken: tr.RefreshToken,
+	}
+	if tr.ExpiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(tr.ExpiresIn) * time.Second)
+	}
+	// 授权服务器可以不轮换刷新令牌，此时继续使用原来的
+	if token.RefreshToken == "" {
+		token.RefreshToken = r.refreshToken
+	}
+	r.refreshToken = token.RefreshToken
+	return token, nil
+}
+
+// persistingTokenSource 在令牌刷新后写回存储
+type persistingTokenSource struct {
+	src       oauth2.TokenSource
+	store     *Store
+	serverURL string
+
+	mu    sync.Mutex
+	creds *Credentials
+}
+
+func (p *persistingTokenSource) Token() (*oauth2.Token, error) {
+	token, err := p.src.Token()
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh token (run 'mcp-cli login' again): %w", err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if token.AccessToken != p.creds.Token.AccessToken {
+		updated := *p.creds
+		updated.Token = token
+		if err := p.store.Save(p.serverURL, &updated); err != nil {
+			return nil, err
+		}
+		p.creds = &updated
+	}
+	return token, nil
+}
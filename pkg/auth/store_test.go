@@ -0,0 +1,154 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/justinwongcn/go-mcp-cli/pkg/secrets"
+
+	"golang.org/x/oauth2"
+)
+
+// Copyright 2025 MCP CLI Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+func TestSaveKeepsClientSecretOutOfTokenFile(t *testing.T) {
+	t.Setenv(secrets.PassphraseEnv, "")
+	dir := t.TempDir()
+	store, err := NewStore(filepath.Join(dir, "tokens.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	creds := &Credentials{
+		ClientID:      "cid",
+		ClientSecret:  "dcr-s3cret",
+		TokenEndpoint: "https://as.example/token",
+		Token:         &oauth2.Token{AccessToken: "at"},
+	}
+	if err := store.Save("https://mcp.example/mcp", creds); err != nil {
+		t.Fatal(err)
+	}
+	if creds.ClientSecret != "dcr-s3cret" {
+		t.Errorf("Save() modified the caller's credentials: %q", creds.ClientSecret)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "tokens.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "dcr-s3cret") {
+		t.Errorf("token store contains the plaintext client secret: %s", data)
+	}
+	loaded, err := store.Load("https://mcp.example/mcp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !secrets.HasReference(loaded.ClientSecret) {
+		t.Fatalf("stored client secret = %q, want a secret reference", loaded.ClientSecret)
+	}
+	if got, err := store.secrets.Resolve(loaded.ClientSecret); err != nil || got != "dcr-s3cret" {
+		t.Errorf("resolved client secret = %q, %v", got, err)
+	}
+
+	if err := store.Delete("https://mcp.example/mcp"); err != nil {
+		t.Fatal(err)
+	}
+	if names, _ := store.secrets.Names(); len(names) != 0 {
+		t.Errorf("client secret left in secret store after Delete(): %v", names)
+	}
+}
+
+func TestTokenSourceRefresh(t *testing.T) {
+	t.Setenv(secrets.PassphraseEnv, "")
+
+	var mu sync.Mutex
+	var forms []url.Values
+	var basicAuth []string
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		user, pass, _ := r.BasicAuth()
+		mu.Lock()
+		forms = append(forms, r.PostForm)
+		basicAuth = append(basicAuth, user+":"+pass)
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"new","token_type":"Bearer","expires_in":3600}`))
+	}))
+	defer srv.Close()
+
+	store, err := NewStore(filepath.Join(t.TempDir(), "tokens.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	const serverURL = "https://mcp.example/mcp"
+	err = store.Save(serverURL, &Credentials{
+		ClientID:      "cid",
+		ClientSecret:  "a b",
+		TokenEndpoint: srv.URL + "/token",
+		Resource:      serverURL,
+		Token:         &oauth2.Token{AccessToken: "old", RefreshToken: "rt", Expiry: time.Now().Add(-time.Minute)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 未使用服务器的 TLS 设置时，刷新请求无法通过证书校验
+	tokens, err := store.TokenSource(serverURL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tokens.Token(); err == nil {
+		t.Fatal("Token() with the default client trusted the test certificate")
+	}
+
+	tokens, err = store.TokenSource(serverURL, srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := tokens.Token()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.AccessToken != "new" || token.RefreshToken != "rt" {
+		t.Errorf("Token() = %q/%q, want new access token and the kept refresh token", token.AccessToken, token.RefreshToken)
+	}
+
+	if len(forms) != 1 {
+		t.Fatalf("token endpoint called %d times, want 1", len(forms))
+	}
+	want := url.Values{"grant_type": {"refresh_token"}, "refresh_token": {"rt"}, "resource": {serverURL}}
+	if !reflect.DeepEqual(forms[0], want) {
+		t.Errorf("refresh request form = %v, want %v", forms[0], want)
+	}
+	if basicAuth[0] != "cid:a+b" {
+		t.Errorf("refresh request basic auth = %q, want form-encoded client credentials", basicAuth[0])
+	}
+
+	saved, err := store.Load(serverURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Token.AccessToken != "new" || !secrets.HasReference(saved.ClientSecret) {
+		t.Errorf("saved credentials after refresh = %+v", saved)
+	}
+}
@@ -0,0 +1,377 @@
+package bridge
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Copyright 2025 MCP CLI Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// sessionHeader Streamable HTTP 会话 ID 请求头
+const sessionHeader = "Mcp-Session-Id"
+
+// Dialer 建立一个到上游服务器的新连接
+type Dialer func(ctx context.Context) (mcp.Connection, error)
+
+// Options 桥接器选项
+type Options struct {
+	// Shared 为 false 时每个下游会话独占一个上游连接，为 true 时所有会话共享同一个上游连接
+	Shared bool
+
+	// MaxSessions 同时存在的会话数上限，达到上限后新会话返回 503，0 表示不限制
+	MaxSessions int
+
+	// IdleTimeout Streamable HTTP 会话超过这个时间没有请求时关闭，0 表示不关闭。
+	// 客户端不一定会发送 DELETE 结束会话，没有超时时每个遗留的会话都会占用一个服务器进程。
+	IdleTimeout time.Duration
+}
+
+// Bridge 将远程客户端的 HTTP/SSE 会话桥接到上游连接，原样转发 JSON-RPC 消息
+type Bridge struct {
+	dial Dialer
+	opts Options
+	mux  *multiplexer // 共享上游时非 nil
+
+	mu       sync.Mutex
+	sessions map[string]*session
+	opening  int // 已占用名额、正在建立的会话数
+}
+
+// session 一个下游会话
+type session struct {
+	handler http.Handler   // 处理该会话后续请求的传输
+	conn    mcp.Connection // 下游连接
+
+	mu          sync.Mutex
+	active      int         // 正在处理的请求数
+	idle        *time.Timer // 空闲超时，未设置 IdleTimeout 时为 nil
+	idleTimeout time.Duration
+}
+
+// New 创建桥接器，opts 为 nil 时使用默认选项（不共享上游，不限制会话数，不关闭空闲会话）
+func New(dial Dialer, opts *Options) *Bridge {
+	b := &Bridge{
+		dial:     dial,
+		sessions: make(map[string]*session),
+	}
+	if opts != nil {
+		b.opts = *opts
+	}
+	if b.opts.Shared {
+		b.mux = newMultiplexer(dial)
+	}
+	return b
+}
+
+// StreamableHandler 返回以 Streamable HTTP 传输提供服务的 HTTP 处理器
+func (b *Bridge) StreamableHandler() http.Handler {
+	return http.HandlerFunc(b.serveStreamable)
+}
+
+// SSEHandler 返回以 SSE 传输（2024-11-05 版本协议）提供服务的 HTTP 处理器
+func (b *Bridge) SSEHandler() http.Handler {
+	return http.HandlerFunc(b.serveSSE)
+}
+
+// Close 关闭所有下游会话及其上游连接
+func (b *Bridge) Close() error {
+	b.mu.Lock()
+	sessions := b.sessions
+	b.sessions = make(map[string]*session)
+	b.mu.Unlock()
+
+	for _, s := range sessions {
+		s.stopIdleTimer()
+		s.conn.Close()
+	}
+	if b.mux != nil {
+		return b.mux.close()
+	}
+	return nil
+}
+
+// serveStreamable 处理 Streamable HTTP 请求：不带会话 ID 的 POST 创建新会话，
+// 其余请求按会话 ID 交给对应的传输处理
+func (b *Bridge) serveStreamable(w http.ResponseWriter, req *http.Request) {
+	if id := req.Header.Get(sessionHeader); id != "" {
+		s := b.session(id)
+		if s == nil {
+			http.Error(w, "session not found", http.StatusNotFound)
+			return
+		}
+		if req.Method == http.MethodDelete {
+			b.removeSession(id)
+			s.conn.Close()
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		s.begin()
+		defer s.end()
+		s.handler.ServeHTTP(w, req)
+		return
+	}
+
+	if req.Method != http.MethodPost {
+		http.Error(w, "missing session ID", http.StatusBadRequest)
+		return
+	}
+	if !b.reserve() {
+		http.Error(w, "too many sessions", http.StatusServiceUnavailable)
+		return
+	}
+
+	up, err := b.openUpstream()
+	if err != nil {
+		b.release()
+		http.Error(w, fmt.Sprintf("failed to start upstream server: %v", err), http.StatusBadGateway)
+		return
+	}
+
+	id := newSessionID()
+	transport := &mcp.StreamableServerTransport{SessionID: id}
+	down, err := transport.Connect(context.Background())
+	if err != nil {
+		b.release()
+		up.Close()
+		http.Error(w, "failed to create session", http.StatusInternalServerError)
+		return
+	}
+
+	s := &session{handler: transport, conn: down, idleTimeout: b.opts.IdleTimeout}
+	if s.idleTimeout > 0 {
+		s.idle = time.AfterFunc(s.idleTimeout, func() { b.expire(id, s) })
+	}
+	b.addSession(id, s)
+	go func() {
+		Pipe(down, up)
+		b.removeSession(id)
+	}()
+
+	s.begin()
+	defer s.end()
+	transport.ServeHTTP(w, req)
+}
+
+// serveSSE 处理 SSE 请求：GET 建立事件流并创建会话，POST 按 sessionid 投递消息
+func (b *Bridge) serveSSE(w http.ResponseWriter, req *http.Request) {
+	if id := req.URL.Query().Get("sessionid"); id != "" {
+		if req.Method != http.MethodPost {
+			http.Error(w, "invalid method", http.StatusMethodNotAllowed)
+			return
+		}
+		s := b.session(id)
+		if s == nil {
+			http.Error(w, "session not found", http.StatusNotFound)
+			return
+		}
+		s.handler.ServeHTTP(w, req)
+		return
+	}
+
+	if req.Method != http.MethodGet {
+		http.Error(w, "invalid method", http.StatusMethodNotAllowed)
+		return
+	}
+	if !b.reserve() {
+		http.Error(w, "too many sessions", http.StatusServiceUnavailable)
+		return
+	}
+
+	up, err := b.openUpstream()
+	if err != nil {
+		b.release()
+		http.Error(w, fmt.Sprintf("failed to start upstream server: %v", err), http.StatusBadGateway)
+		return
+	}
+
+	id := newSessionID()
+	endpoint, err := req.URL.Parse("?sessionid=" + id)
+	if err != nil {
+		b.release()
+		up.Close()
+		http.Error(w, "failed to create endpoint", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	transport := &mcp.SSEServerTransport{Endpoint: endpoint.RequestURI(), Response: w}
+	down, err := transport.Connect(req.Context())
+	if err != nil {
+		b.release()
+		up.Close()
+		return
+	}
+
+	// 会话在 GET 请求结束时终止
+	b.addSession(id, &session{handler: transport, conn: down})
+	defer b.removeSession(id)
+
+	done := make(chan struct{})
+	go func() {
+		Pipe(down, up)
+		close(done)
+	}()
+
+	select {
+	case <-req.Context().Done():
+		down.Close()
+		<-done
+	case <-done:
+	}
+}
+
+// openUpstream 为新的下游会话打开上游连接
+func (b *Bridge) openUpstream() (mcp.Connection, error) {
+	// 上游连接在会话期间保持打开，不能绑定到单个 HTTP 请求的上下文
+	if b.mux != nil {
+		return b.mux.open(context.Background())
+	}
+	return b.dial(context.Background())
+}
+
+func (b *Bridge) session(id string) *session {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.sessions[id]
+}
+
+// reserve 为新会话占用一个名额，达到 MaxSessions 时返回 false。
+// 名额在 addSession 时转给会话，建立会话失败时需调用 release 归还。
+func (b *Bridge) reserve() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.opts.MaxSessions > 0 && len(b.sessions)+b.opening >= b.opts.MaxSessions {
+		return false
+	}
+	b.opening++
+	return true
+}
+
+func (b *Bridge) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.opening--
+}
+
+func (b *Bridge) addSession(id string, s *session) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.opening--
+	b.sessions[id] = s
+}
+
+func (b *Bridge) removeSession(id string) {
+	b.mu.Lock()
+	s := b.sessions[id]
+	delete(b.sessions, id)
+	b.mu.Unlock()
+
+	if s != nil {
+		s.stopIdleTimer()
+	}
+}
+
+// expire 在空闲超时后关闭会话，关闭下游连接会让 Pipe 一并关闭上游连接
+func (b *Bridge) expire(id string, s *session) {
+	s.mu.Lock()
+	active := s.active
+	s.mu.Unlock()
+	if active > 0 {
+		return
+	}
+	b.removeSession(id)
+	s.conn.Close()
+}
+
+// begin 标记会话开始处理一个请求，请求（包括长时间打开的 GET 事件流）期间不计空闲时间
+func (s *session) begin() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.active++
+	if s.idle != nil {
+		s.idle.Stop()
+	}
+}
+
+// end 标记请求处理结束，没有进行中的请求时重新开始计算空闲时间
+func (s *session) end() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.active--
+	if s.active == 0 && s.idle != nil {
+		s.idle.Reset(s.idleTimeout)
+	}
+}
+
+func (s *session) stopIdleTimer() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.idle != nil {
+		s.idle.Stop()
+	}
+}
+
+// Pipe 在两个连接之间双向转发消息，任一方向读取结束时关闭两个连接并返回
+func Pipe(a, b mcp.Connection) {
+	var once sync.Once
+	closeBoth := func() {
+		a.Close()
+		b.Close()
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		forward(b, a)
+		once.Do(closeBoth)
+	}()
+	go func() {
+		defer wg.Done()
+		forward(a, b)
+		once.Do(closeBoth)
+	}()
+	wg.Wait()
+}
+
+// forward 将 src 读到的消息逐条写入 dst，直到 src 读取失败。
+// 单条消息写入失败（如客户端已断开对应的请求流）不影响后续转发。
+func forward(dst, src mcp.Connection) {
+	ctx := context.Background()
+	for {
+		msg, err := src.Read(ctx)
+		if err != nil {
+			return
+		}
+		dst.Write(ctx, msg)
+	}
+}
+
+// newSessionID 生成随机会话 ID
+func newSessionID() string {
+	buf := make([]byte, 16)
+	rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,463 @@
+package bridge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Copyright 2025 MCP CLI Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// sessionBufferSize 每个下游会话可缓冲的上游消息数，缓冲区满的会话会被断开
+const sessionBufferSize = 100
+
+// multiplexer 让多个下游会话共享同一个上游连接。
+//
+// 下游请求 ID 和进度令牌会被改写为上游连接内唯一的值，响应和进度通知再映射回原会话；
+// 上游只初始化一次：第一个 initialize 请求转发给上游，之后的请求等待并返回缓存的结果。
+// 服务器发起的请求交给最近加入的会话处理，针对它的取消通知也只发给该会话，其他通知广播给所有会话。
+//
+// 所有会话共用一个读取上游的循环，读取过慢、缓冲区已满的会话会被断开，不会拖住其他会话。
+type multiplexer struct {
+	dial Dialer
+
+	mu          sync.Mutex
+	conn        mcp.Connection // 上游连接，断开后为 nil
+	nextID      int64
+	pending     map[string]pendingCall // 上游请求 ID 的键（见 idKey）-> 下游请求
+	serverCalls map[string]*sharedConn // 服务器发起的请求 ID -> 处理它的会话
+	sessions    []*sharedConn
+	initResult  json.RawMessage // 缓存的 initialize 结果
+	initKey     string          // 正在等待响应的 initialize 的上游请求 ID 的键
+	initWait    chan struct{}   // 上述请求得到响应或上游断开时关闭
+	initialized bool            // notifications/initialized 是否已转发
+}
+
+// pendingCall 一个等待上游响应的下游请求
+type pendingCall struct {
+	session       *sharedConn
+	id            jsonrpc.ID      // 下游请求 ID
+	upstreamID    jsonrpc.ID      // 转发给上游时使用的请求 ID
+	progressToken json.RawMessage // 下游的进度令牌，未请求进度通知时为 nil
+}
+
+func newMultiplexer(dial Dialer) *multiplexer {
+	return &multiplexer{
+		dial:        dial,
+		pending:     make(map[string]pendingCall),
+		serverCalls: make(map[string]*sharedConn),
+	}
+}
+
+// open 为一个下游会话创建共享上游的虚拟连接，必要时先建立上游连接
+func (m *multiplexer) open(ctx context.Context) (mcp.Connection, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.conn == nil {
+		conn, err := m.dial(ctx)
+		if err != nil {
+			return nil, err
+		}
+		m.conn = conn
+		go m.readLoop(conn)
+	}
+
+	s := &sharedConn{
+		m:        m,
+		incoming: make(chan jsonrpc.Message, sessionBufferSize),
+		done:     make(chan struct{}),
+	}
+	m.sessions = append(m.sessions, s)
+	return s, nil
+}
+
+// close 关闭上游连接及所有下游会话
+func (m *multiplexer) close() error {
+	m.mu.Lock()
+	conn := m.conn
+	m.mu.Unlock()
+
+	if conn == nil {
+		return nil
+	}
+	m.reset(conn)
+	return conn.Close()
+}
+
+// readLoop 读取上游消息并分发给下游会话，上游断开时重置状态
+func (m *multiplexer) readLoop(conn mcp.Connection) {
+	ctx := context.Background()
+	for {
+		msg, err := conn.Read(ctx)
+		if err != nil {
+			m.reset(conn)
+			conn.Close()
+			return
+		}
+		m.dispatch(ctx, conn, msg)
+	}
+}
+
+// reset 在上游连接断开后关闭所有会话，下一个会话会重新建立上游连接
+func (m *multiplexer) reset(conn mcp.Connection) {
+	m.mu.Lock()
+	if m.conn != conn {
+		m.mu.Unlock()
+		return
+	}
+	sessions := m.sessions
+	m.conn = nil
+	m.sessions = nil
+	m.pending = make(map[string]pendingCall)
+	m.serverCalls = make(map[string]*sharedConn)
+	m.initResult = nil
+	if m.initWait != nil {
+		close(m.initWait)
+	}
+	m.initKey, m.initWait = "", nil
+	m.initialized = false
+	m.mu.Unlock()
+
+	for _, s := range sessions {
+		s.shutdown()
+	}
+}
+
+// dispatch 将一条上游消息路由到对应的下游会话
+func (m *multiplexer) dispatch(ctx context.Context, conn mcp.Connection, msg jsonrpc.Message) {
+	switch msg := msg.(type) {
+	case *jsonrpc.Response:
+		key := idKey(msg.ID)
+		m.mu.Lock()
+		call, ok := m.pending[key]
+		delete(m.pending, key)
+		if key == m.initKey {
+			if msg.Error == nil {
+				m.initResult = msg.Result
+			}
+			close(m.initWait)
+			m.initKey, m.initWait = "", nil
+		}
+		m.mu.Unlock()
+
+		if ok {
+			call.session.deliver(&jsonrpc.Response{ID: call.id, Result: msg.Result, Error: msg.Error})
+		}
+
+	case *jsonrpc.Request:
+		if !msg.IsCall() {
+			m.dispatchNotification(msg)
+			return
+		}
+
+		// 服务器发起的请求（如 sampling、roots/list）只能由一个客户端应答
+		m.mu.Lock()
+		var target *sharedConn
+		if len(m.sessions) > 0 {
+			target = m.sessions[len(m.sessions)-1]
+			m.serverCalls[idKey(msg.ID)] = target
+		}
+		m.mu.Unlock()
+
+		if target == nil {
+			conn.Write(ctx, &jsonrpc.Response{
+				ID:    msg.ID,
+				Error: &jsonrpc.Error{Code: jsonrpc.CodeInternalError, Message: "no client connected"},
+			})
+			return
+		}
+		target.deliver(msg)
+	}
+}
+
+// dispatchNotification 将上游通知发给相关的会话：进度通知发给发起请求的会话，
+// 取消通知发给处理该请求的会话，其他通知广播给所有会话
+func (m *multiplexer) dispatchNotification(msg *jsonrpc.Request) {
+	var params map[string]json.RawMessage
+	switch msg.Method {
+	case "notifications/progress", "notifications/cancelled":
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return
+		}
+	}
+
+	m.mu.Lock()
+	var targets []*sharedConn
+	switch msg.Method {
+	case "notifications/progress":
+		// 转发时进度令牌被改写为上游请求 ID
+		token, err := decodeID(params["progressToken"])
+		if err != nil {
+			break
+		}
+		call, ok := m.pending[idKey(token)]
+		if !ok || call.progressToken == nil {
+			break
+		}
+		params["progressToken"] = call.progressToken
+		data, err := json.Marshal(params)
+		if err != nil {
+			break
+		}
+		msg = &jsonrpc.Request{Method: msg.Method, Params: data}
+		targets = []*sharedConn{call.session}
+
+	case "notifications/cancelled":
+		id, err := decodeID(params["requestId"])
+		if err != nil {
+			break
+		}
+		if s, ok := m.serverCalls[idKey(id)]; ok {
+			delete(m.serverCalls, idKey(id))
+			targets = []*sharedConn{s}
+		}
+
+	default:
+		targets = append(targets, m.sessions...)
+	}
+	m.mu.Unlock()
+
+	for _, s := range targets {
+		s.deliver(msg)
+	}
+}
+
+// send 将一条下游消息改写后发往上游
+func (m *multiplexer) send(ctx context.Context, s *sharedConn, msg jsonrpc.Message) error {
+	m.mu.Lock()
+	conn := m.conn
+	if conn == nil {
+		m.mu.Unlock()
+		return io.EOF
+	}
+
+	switch req := msg.(type) {
+	case *jsonrpc.Request:
+		if req.IsCall() {
+			if req.Method == "initialize" {
+				// 其他会话的 initialize 正在进行时等待它的结果；它失败时由本请求重新初始化
+				for m.initResult == nil && m.initWait != nil {
+					wait := m.initWait
+					m.mu.Unlock()
+					select {
+					case <-wait:
+					case <-ctx.Done():
+						return ctx.Err()
+					case <-s.done:
+						return io.EOF
+					}
+					m.mu.Lock()
+					if m.conn != conn {
+						m.mu.Unlock()
+						return io.EOF
+					}
+				}
+				if m.initResult != nil {
+					result := m.initResult
+					m.mu.Unlock()
+					s.deliver(&jsonrpc.Response{ID: req.ID, Result: result})
+					return nil
+				}
+			}
+
+			m.nextID++
+			key := fmt.Sprintf("bridge-%d", m.nextID)
+			upstreamID, err := jsonrpc.MakeID(key)
+			if err != nil {
+				m.mu.Unlock()
+				return err
+			}
+			// 不同会话可能使用相同的进度令牌，改写为与上游请求 ID 相同的唯一值
+			params, progressToken := rewriteProgressToken(req.Params, key)
+			m.pending[idKey(upstreamID)] = pendingCall{session: s, id: req.ID, upstreamID: upstreamID, progressToken: progressToken}
+			if req.Method == "initialize" {
+				m.initKey, m.initWait = idKey(upstreamID), make(chan struct{})
+			}
+			msg = &jsonrpc.Request{ID: upstreamID, Method: req.Method, Params: params}
+			break
+		}
+
+		switch req.Method {
+		case "notifications/initialized":
+			if m.initialized {
+				m.mu.Unlock()
+				return nil
+			}
+			m.initialized = true
+		case "notifications/cancelled":
+			msg = m.rewriteCancel(s, req)
+		}
+
+	case *jsonrpc.Response:
+		delete(m.serverCalls, idKey(req.ID))
+	}
+	m.mu.Unlock()
+
+	return conn.Write(ctx, msg)
+}
+
+// rewriteCancel 将取消通知中的下游请求 ID 替换为上游请求 ID，调用方需持有锁
+func (m *multiplexer) rewriteCancel(s *sharedConn, req *jsonrpc.Request) *jsonrpc.Request {
+	var params map[string]json.RawMessage
+	if err := json.Unmarshal(req.Params, &params); err != nil {
+		return req
+	}
+	id, err := decodeID(params["requestId"])
+	if err != nil {
+		return req
+	}
+	key := idKey(id)
+	for _, call := range m.pending {
+		if call.session == s && idKey(call.id) == key {
+			params["requestId"], _ = json.Marshal(call.upstreamID.Raw())
+			data, err := json.Marshal(params)
+			if err != nil {
+				return req
+			}
+			return &jsonrpc.Request{Method: req.Method, Params: data}
+		}
+	}
+	return req
+}
+
+// rewriteProgressToken 将请求参数 _meta.progressToken 替换为 token，返回新的参数和原来的令牌。
+// 未带进度令牌时原样返回参数，令牌为 nil。
+func rewriteProgressToken(params json.RawMessage, token string) (json.RawMessage, json.RawMessage) {
+	if !bytes.Contains(params, []byte(`"progressToken"`)) {
+		return params, nil
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(params, &fields); err != nil {
+		return params, nil
+	}
+	var meta map[string]json.RawMessage
+	if err := json.Unmarshal(fields["_meta"], &meta); err != nil || meta["progressToken"] == nil {
+		return params, nil
+	}
+
+	original := meta["progressToken"]
+	meta["progressToken"], _ = json.Marshal(token)
+	var err error
+	if fields["_meta"], err = json.Marshal(meta); err != nil {
+		return params, nil
+	}
+	rewritten, err := json.Marshal(fields)
+	if err != nil {
+		return params, nil
+	}
+	return rewritten, original
+}
+
+// detach 将会话从共享上游移除
+func (m *multiplexer) detach(s *sharedConn) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, session := range m.sessions {
+		if session == s {
+			m.sessions = append(m.sessions[:i], m.sessions[i+1:]...)
+			break
+		}
+	}
+	for key, call := range m.pending {
+		if call.session == s {
+			delete(m.pending, key)
+		}
+	}
+	for key, session := range m.serverCalls {
+		if session == s {
+			delete(m.serverCalls, key)
+		}
+	}
+}
+
+// sharedConn 共享上游连接上的一个下游会话，实现 mcp.Connection 接口
+type sharedConn struct {
+	m        *multiplexer
+	incoming chan jsonrpc.Message
+
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+// Read 实现 mcp.Connection 接口
+func (c *sharedConn) Read(ctx context.Context) (jsonrpc.Message, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case msg := <-c.incoming:
+		return msg, nil
+	case <-c.done:
+		return nil, io.EOF
+	}
+}
+
+// Write 实现 mcp.Connection 接口
+func (c *sharedConn) Write(ctx context.Context, msg jsonrpc.Message) error {
+	select {
+	case <-c.done:
+		return io.EOF
+	default:
+	}
+	return c.m.send(ctx, c, msg)
+}
+
+// Close 实现 mcp.Connection 接口，只断开本会话，不关闭共享的上游连接
+func (c *sharedConn) Close() error {
+	c.m.detach(c)
+	c.shutdown()
+	return nil
+}
+
+// SessionID 实现 mcp.Connection 接口
+func (c *sharedConn) SessionID() string { return "" }
+
+// deliver 将上游消息投递给本会话，缓冲区已满时断开本会话，避免阻塞读取上游的循环
+func (c *sharedConn) deliver(msg jsonrpc.Message) {
+	select {
+	case c.incoming <- msg:
+	case <-c.done:
+	default:
+		c.Close()
+	}
+}
+
+func (c *sharedConn) shutdown() {
+	c.closeOnce.Do(func() { close(c.done) })
+}
+
+// decodeID 解析 JSON 中的请求 ID（字符串或数字）
+func decodeID(data json.RawMessage) (jsonrpc.ID, error) {
+	var raw any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return jsonrpc.ID{}, err
+	}
+	return jsonrpc.MakeID(raw)
+}
+
+// idKey 返回请求 ID 在映射表中的键。键带有类型前缀，字符串 "1" 与数字 1 是不同的 ID
+func idKey(id jsonrpc.ID) string {
+	if s, ok := id.Raw().(string); ok {
+		return "s:" + s
+	}
+	return "n:" + fmt.Sprint(id.Raw())
+}
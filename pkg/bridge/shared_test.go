@@ -0,0 +1,566 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Copyright 2025 MCP CLI Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// fakeUpstream 模拟上游连接：multiplexer 写入的消息进入 written，send 的消息由 Read 返回
+type fakeUpstream struct {
+	written  chan jsonrpc.Message
+	incoming chan jsonrpc.Message
+
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+func newFakeUpstream() *fakeUpstream {
+	return &fakeUpstream{
+		written:  make(chan jsonrpc.Message, 100),
+		incoming: make(chan jsonrpc.Message),
+		done:     make(chan struct{}),
+	}
+}
+
+func (f *fakeUpstream) Read(ctx context.Context) (jsonrpc.Message, error) {
+	select {
+	case msg := <-f.incoming:
+		return msg, nil
+	case <-f.done:
+		return nil, io.EOF
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (f *fakeUpstream) Write(ctx context.Context, msg jsonrpc.Message) error {
+	f.written <- msg
+	return nil
+}
+
+func (f *fakeUpstream) Close() error {
+	f.closeOnce.Do(func() { close(f.done) })
+	return nil
+}
+
+func (f *fakeUpstream) SessionID() string { return "" }
+
+// send 模拟上游发来一条消息，返回时 readLoop 已开始处理它
+func (f *fakeUpstream) send(t *testing.T, msg jsonrpc.Message) {
+	t.Helper()
+	select {
+	case f.incoming <- msg:
+	case <-time.After(time.Second):
+		t.Fatal("upstream message was not read")
+	}
+}
+
+// next 返回 multiplexer 写给上游的下一条消息
+func (f *fakeUpstream) next(t *testing.T) jsonrpc.Message {
+	t.Helper()
+	select {
+	case msg := <-f.written:
+		return msg
+	case <-time.After(time.Second):
+		t.Fatal("no message written upstream")
+		return nil
+	}
+}
+
+// expectNone 确认上游在短时间内没有收到消息
+func (f *fakeUpstream) expectNone(t *testing.T) {
+	t.Helper()
+	select {
+	case msg := <-f.written:
+		t.Fatalf("unexpected upstream message: %+v", msg)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func newTestMultiplexer(t *testing.T) (*multiplexer, *fakeUpstream) {
+	t.Helper()
+	upstream := newFakeUpstream()
+	m := newMultiplexer(func(ctx context.Context) (mcp.Connection, error) {
+		return upstream, nil
+	})
+	t.Cleanup(func() { m.close() })
+	return m, upstream
+}
+
+func openSession(t *testing.T, m *multiplexer) mcp.Connection {
+	t.Helper()
+	conn, err := m.open(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return conn
+}
+
+// readMessage 读取会话收到的下一条消息
+func readMessage(t *testing.T, conn mcp.Connection) jsonrpc.Message {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	msg, err := conn.Read(ctx)
+	if err != nil {
+		t.Fatalf("session read: %v", err)
+	}
+	return msg
+}
+
+// expectNoMessage 确认会话在短时间内没有收到消息
+func expectNoMessage(t *testing.T, conn mcp.Connection) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if msg, err := conn.Read(ctx); err == nil {
+		t.Fatalf("unexpected session message: %+v", msg)
+	}
+}
+
+func writeMessage(t *testing.T, conn mcp.Connection, msg jsonrpc.Message) {
+	t.Helper()
+	if err := conn.Write(context.Background(), msg); err != nil {
+		t.Fatalf("session write: %v", err)
+	}
+}
+
+func mustID(t *testing.T, v any) jsonrpc.ID {
+	t.Helper()
+	id, err := jsonrpc.MakeID(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+// idJSON 返回请求 ID 的 JSON 表示
+func idJSON(t *testing.T, id jsonrpc.ID) string {
+	t.Helper()
+	data, err := json.Marshal(id.Raw())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func mustParams(t *testing.T, raw string) json.RawMessage {
+	t.Helper()
+	if !json.Valid([]byte(raw)) {
+		t.Fatalf("invalid params %s", raw)
+	}
+	return json.RawMessage(raw)
+}
+
+// paramField 返回消息参数中的一个字段的 JSON 文本
+func paramField(t *testing.T, msg jsonrpc.Message, path ...string) string {
+	t.Helper()
+	req, ok := msg.(*jsonrpc.Request)
+	if !ok {
+		t.Fatalf("message %+v is not a request", msg)
+	}
+	raw := req.Params
+	for _, key := range path {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &fields); err != nil {
+			t.Fatalf("params %s: %v", req.Params, err)
+		}
+		raw = fields[key]
+	}
+	return string(raw)
+}
+
+func TestMultiplexerRewritesRequestIDs(t *testing.T) {
+	m, upstream := newTestMultiplexer(t)
+	a, b := openSession(t, m), openSession(t, m)
+
+	// 两个会话使用相同的请求 ID，数字和字符串 ID 都要原样还原
+	tests := []struct {
+		session mcp.Connection
+		id      jsonrpc.ID
+		result  string
+	}{
+		{session: a, id: mustID(t, float64(1)), result: `{"from":"a"}`},
+		{session: b, id: mustID(t, float64(1)), result: `{"from":"b"}`},
+		{session: b, id: mustID(t, "x"), result: `{"from":"b-x"}`},
+	}
+
+	upstreamIDs := make([]jsonrpc.ID, len(tests))
+	seen := make(map[string]bool)
+	for i, tt := range tests {
+		writeMessage(t, tt.session, &jsonrpc.Request{ID: tt.id, Method: "tools/list"})
+		req := upstream.next(t).(*jsonrpc.Request)
+		key := idKey(req.ID)
+		if seen[key] {
+			t.Fatalf("upstream ID %q reused", key)
+		}
+		seen[key] = true
+		upstreamIDs[i] = req.ID
+	}
+
+	// 上游乱序应答
+	for _, i := range []int{2, 0, 1} {
+		upstream.send(t, &jsonrpc.Response{ID: upstreamIDs[i], Result: json.RawMessage(tests[i].result)})
+	}
+	for _, i := range []int{2, 0, 1} {
+		tt := tests[i]
+		resp, ok := readMessage(t, tt.session).(*jsonrpc.Response)
+		if !ok {
+			t.Fatalf("request %d: expected response", i)
+		}
+		if resp.ID != tt.id || string(resp.Result) != tt.result {
+			t.Errorf("request %d: got ID %v result %s, want ID %v result %s", i, resp.ID.Raw(), resp.Result, tt.id.Raw(), tt.result)
+		}
+	}
+	expectNoMessage(t, a)
+	expectNoMessage(t, b)
+}
+
+func TestMultiplexerRewritesCancel(t *testing.T) {
+	m, upstream := newTestMultiplexer(t)
+	a, b := openSession(t, m), openSession(t, m)
+
+	writeMessage(t, a, &jsonrpc.Request{ID: mustID(t, float64(7)), Method: "tools/call"})
+	upA := upstream.next(t).(*jsonrpc.Request)
+	writeMessage(t, b, &jsonrpc.Request{ID: mustID(t, float64(7)), Method: "tools/call"})
+	upB := upstream.next(t).(*jsonrpc.Request)
+
+	tests := []struct {
+		name    string
+		session mcp.Connection
+		params  string
+		want    string
+	}{
+		{name: "session b", session: b, params: `{"requestId":7,"reason":"stop"}`, want: idJSON(t, upB.ID)},
+		{name: "session a", session: a, params: `{"requestId":7}`, want: idJSON(t, upA.ID)},
+		{name: "unknown request", session: a, params: `{"requestId":8,"reason":"stop"}`, want: `8`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writeMessage(t, tt.session, &jsonrpc.Request{Method: "notifications/cancelled", Params: mustParams(t, tt.params)})
+			msg := upstream.next(t)
+			if got := paramField(t, msg, "requestId"); got != tt.want {
+				t.Errorf("requestId = %s, want %s", got, tt.want)
+			}
+			// 其他参数原样保留
+			var want map[string]json.RawMessage
+			json.Unmarshal([]byte(tt.params), &want)
+			if got := paramField(t, msg, "reason"); got != string(want["reason"]) {
+				t.Errorf("reason = %s, want %s", got, want["reason"])
+			}
+		})
+	}
+}
+
+func TestMultiplexerRoutesProgress(t *testing.T) {
+	m, upstream := newTestMultiplexer(t)
+	a, b := openSession(t, m), openSession(t, m)
+
+	// 两个会话使用相同的进度令牌
+	writeMessage(t, a, &jsonrpc.Request{ID: mustID(t, float64(1)), Method: "tools/call",
+		Params: mustParams(t, `{"name":"slow","_meta":{"progressToken":"tok"}}`)})
+	upA := upstream.next(t)
+	writeMessage(t, b, &jsonrpc.Request{ID: mustID(t, float64(1)), Method: "tools/call",
+		Params: mustParams(t, `{"name":"slow","_meta":{"progressToken":"tok"}}`)})
+	upB := upstream.next(t)
+	writeMessage(t, b, &jsonrpc.Request{ID: mustID(t, float64(2)), Method: "tools/call",
+		Params: mustParams(t, `{"name":"slow","_meta":{"progressToken":5}}`)})
+	upB2 := upstream.next(t)
+
+	tokens := make(map[string]bool)
+	for _, msg := range []jsonrpc.Message{upA, upB, upB2} {
+		token := paramField(t, msg, "_meta", "progressToken")
+		if token != idJSON(t, msg.(*jsonrpc.Request).ID) {
+			t.Errorf("upstream progressToken = %s, want the upstream request ID", token)
+		}
+		if paramField(t, msg, "name") != `"slow"` {
+			t.Errorf("other params were not preserved: %s", msg.(*jsonrpc.Request).Params)
+		}
+		tokens[token] = true
+	}
+	if len(tokens) != 3 {
+		t.Fatalf("upstream progress tokens are not unique: %v", tokens)
+	}
+
+	tests := []struct {
+		name     string
+		upstream jsonrpc.Message
+		session  mcp.Connection
+		other    mcp.Connection
+		want     string
+	}{
+		{name: "string token to a", upstream: upA, session: a, other: b, want: `"tok"`},
+		{name: "string token to b", upstream: upB, session: b, other: a, want: `"tok"`},
+		{name: "numeric token to b", upstream: upB2, session: b, other: a, want: `5`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := paramField(t, tt.upstream, "_meta", "progressToken")
+			upstream.send(t, &jsonrpc.Request{Method: "notifications/progress",
+				Params: mustParams(t, `{"progressToken":`+token+`,"progress":1,"total":2}`)})
+			msg := readMessage(t, tt.session)
+			if got := paramField(t, msg, "progressToken"); got != tt.want {
+				t.Errorf("progressToken = %s, want %s", got, tt.want)
+			}
+			if got := paramField(t, msg, "total"); got != "2" {
+				t.Errorf("total = %s, want 2", got)
+			}
+			expectNoMessage(t, tt.other)
+		})
+	}
+
+	// 请求完成后的进度通知不再投递
+	token := paramField(t, upA, "_meta", "progressToken")
+	upstream.send(t, &jsonrpc.Response{ID: upA.(*jsonrpc.Request).ID, Result: json.RawMessage(`{}`)})
+	readMessage(t, a)
+	upstream.send(t, &jsonrpc.Request{Method: "notifications/progress", Params: mustParams(t, `{"progressToken":`+token+`,"progress":2}`)})
+	expectNoMessage(t, a)
+	expectNoMessage(t, b)
+}
+
+func TestMultiplexerInitializesOnce(t *testing.T) {
+	m, upstream := newTestMultiplexer(t)
+	a, b := openSession(t, m), openSession(t, m)
+
+	writeMessage(t, a, &jsonrpc.Request{ID: mustID(t, float64(1)), Method: "initialize", Params: mustParams(t, `{}`)})
+	initReq := upstream.next(t).(*jsonrpc.Request)
+
+	// 第二个 initialize 等待第一个的结果，不发往上游
+	written := make(chan error, 1)
+	go func() {
+		written <- b.Write(context.Background(), &jsonrpc.Request{ID: mustID(t, "init-b"), Method: "initialize", Params: mustParams(t, `{}`)})
+	}()
+	upstream.expectNone(t)
+
+	result := `{"protocolVersion":"2025-06-18"}`
+	upstream.send(t, &jsonrpc.Response{ID: initReq.ID, Result: json.RawMessage(result)})
+	if err := <-written; err != nil {
+		t.Fatalf("second initialize: %v", err)
+	}
+	for _, tt := range []struct {
+		session mcp.Connection
+		id      jsonrpc.ID
+	}{{a, mustID(t, float64(1))}, {b, mustID(t, "init-b")}} {
+		resp := readMessage(t, tt.session).(*jsonrpc.Response)
+		if resp.ID != tt.id || string(resp.Result) != result {
+			t.Errorf("initialize response = %v %s, want %v %s", resp.ID.Raw(), resp.Result, tt.id.Raw(), result)
+		}
+	}
+
+	// 之后加入的会话直接得到缓存的结果
+	c := openSession(t, m)
+	writeMessage(t, c, &jsonrpc.Request{ID: mustID(t, float64(9)), Method: "initialize", Params: mustParams(t, `{}`)})
+	if resp := readMessage(t, c).(*jsonrpc.Response); string(resp.Result) != result {
+		t.Errorf("cached initialize result = %s", resp.Result)
+	}
+
+	// notifications/initialized 只转发一次
+	for _, s := range []mcp.Connection{a, b, c} {
+		writeMessage(t, s, &jsonrpc.Request{Method: "notifications/initialized"})
+	}
+	if msg := upstream.next(t).(*jsonrpc.Request); msg.Method != "notifications/initialized" {
+		t.Errorf("upstream got %s", msg.Method)
+	}
+	upstream.expectNone(t)
+}
+
+func TestMultiplexerRetriesFailedInitialize(t *testing.T) {
+	m, upstream := newTestMultiplexer(t)
+	a, b := openSession(t, m), openSession(t, m)
+
+	writeMessage(t, a, &jsonrpc.Request{ID: mustID(t, float64(1)), Method: "initialize"})
+	first := upstream.next(t).(*jsonrpc.Request)
+	go b.Write(context.Background(), &jsonrpc.Request{ID: mustID(t, float64(1)), Method: "initialize"})
+	upstream.expectNone(t)
+
+	// 第一个 initialize 失败后，等待中的请求自行发往上游
+	upstream.send(t, &jsonrpc.Response{ID: first.ID, Error: &jsonrpc.Error{Code: jsonrpc.CodeInvalidParams, Message: "bad"}})
+	if resp := readMessage(t, a).(*jsonrpc.Response); resp.Error == nil {
+		t.Error("first initialize should fail")
+	}
+	second := upstream.next(t).(*jsonrpc.Request)
+	if second.Method != "initialize" || idKey(second.ID) == idKey(first.ID) {
+		t.Fatalf("retry = %s %v", second.Method, second.ID.Raw())
+	}
+	upstream.send(t, &jsonrpc.Response{ID: second.ID, Result: json.RawMessage(`{}`)})
+	if resp := readMessage(t, b).(*jsonrpc.Response); resp.Error != nil {
+		t.Errorf("second initialize failed: %v", resp.Error)
+	}
+}
+
+func TestMultiplexerServerRequests(t *testing.T) {
+	m, upstream := newTestMultiplexer(t)
+	a, b := openSession(t, m), openSession(t, m)
+
+	// 服务器发起的请求交给最近加入的会话，应答原样转发
+	upstream.send(t, &jsonrpc.Request{ID: mustID(t, float64(3)), Method: "roots/list"})
+	req := readMessage(t, b).(*jsonrpc.Request)
+	if req.Method != "roots/list" || req.ID != mustID(t, float64(3)) {
+		t.Fatalf("server request = %s %v", req.Method, req.ID.Raw())
+	}
+	expectNoMessage(t, a)
+
+	// 针对该请求的取消通知只发给处理它的会话
+	upstream.send(t, &jsonrpc.Request{ID: mustID(t, float64(4)), Method: "sampling/createMessage"})
+	readMessage(t, b)
+	upstream.send(t, &jsonrpc.Request{Method: "notifications/cancelled", Params: mustParams(t, `{"requestId":4}`)})
+	if msg := readMessage(t, b).(*jsonrpc.Request); msg.Method != "notifications/cancelled" {
+		t.Errorf("b got %s, want cancellation", msg.Method)
+	}
+	expectNoMessage(t, a)
+
+	writeMessage(t, b, &jsonrpc.Response{ID: req.ID, Result: json.RawMessage(`{"roots":[]}`)})
+	if resp := upstream.next(t).(*jsonrpc.Response); resp.ID != req.ID {
+		t.Errorf("upstream response ID = %v", resp.ID.Raw())
+	}
+
+	// 其他通知广播给所有会话
+	upstream.send(t, &jsonrpc.Request{Method: "notifications/tools/list_changed"})
+	for _, s := range []mcp.Connection{a, b} {
+		if msg := readMessage(t, s).(*jsonrpc.Request); msg.Method != "notifications/tools/list_changed" {
+			t.Errorf("got %s, want list_changed", msg.Method)
+		}
+	}
+
+	// 没有会话时服务器请求直接返回错误
+	a.Close()
+	b.Close()
+	upstream.send(t, &jsonrpc.Request{ID: mustID(t, float64(5)), Method: "roots/list"})
+	if resp := upstream.next(t).(*jsonrpc.Response); resp.Error == nil {
+		t.Error("expected an error response with no sessions")
+	}
+}
+
+func TestMultiplexerDisconnectsSlowSession(t *testing.T) {
+	m, upstream := newTestMultiplexer(t)
+	slow, fast := openSession(t, m), openSession(t, m)
+
+	// slow 从不读取，缓冲区满后被断开，不影响 fast
+	for i := 0; i <= sessionBufferSize; i++ {
+		upstream.send(t, &jsonrpc.Request{Method: "notifications/message"})
+		readMessage(t, fast)
+	}
+	upstream.send(t, &jsonrpc.Request{Method: "notifications/message"})
+	readMessage(t, fast)
+
+	if err := slow.Write(context.Background(), &jsonrpc.Request{Method: "notifications/initialized"}); err != io.EOF {
+		t.Errorf("write on disconnected session = %v, want EOF", err)
+	}
+	m.mu.Lock()
+	sessions := len(m.sessions)
+	m.mu.Unlock()
+	if sessions != 1 {
+		t.Errorf("sessions = %d, want 1", sessions)
+	}
+}
+
+func TestMultiplexerUpstreamClosed(t *testing.T) {
+	m, upstream := newTestMultiplexer(t)
+	a := openSession(t, m)
+
+	writeMessage(t, a, &jsonrpc.Request{ID: mustID(t, float64(1)), Method: "initialize"})
+	upstream.next(t)
+	upstream.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := a.Read(ctx); err != io.EOF {
+		t.Errorf("read after upstream closed = %v, want EOF", err)
+	}
+}
+
+func TestDecodeID(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    string
+		wantErr bool
+	}{
+		{raw: `7`, want: "n:7"},
+		{raw: `"abc"`, want: "s:abc"},
+		{raw: `{}`, wantErr: true},
+		{raw: `nope`, wantErr: true},
+	}
+	for _, tt := range tests {
+		id, err := decodeID(json.RawMessage(tt.raw))
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("decodeID(%s) = %v, want error", tt.raw, id.Raw())
+			}
+			continue
+		}
+		if err != nil || idKey(id) != tt.want {
+			t.Errorf("decodeID(%s) = %v, %v, want %s", tt.raw, id.Raw(), err, tt.want)
+		}
+	}
+}
+
+func TestIDKey(t *testing.T) {
+	tests := []struct {
+		a, b any
+		same bool
+	}{
+		{a: "1", b: float64(1), same: false},
+		{a: "n:1", b: float64(1), same: false},
+		{a: float64(1), b: float64(1), same: true},
+		{a: "x", b: "x", same: true},
+	}
+	for _, tt := range tests {
+		a, b := idKey(mustID(t, tt.a)), idKey(mustID(t, tt.b))
+		if (a == b) != tt.same {
+			t.Errorf("idKey(%#v) = %q, idKey(%#v) = %q, want same = %v", tt.a, a, tt.b, b, tt.same)
+		}
+	}
+}
+
+func TestMultiplexerCancelDistinguishesIDTypes(t *testing.T) {
+	m, upstream := newTestMultiplexer(t)
+	a := openSession(t, m)
+
+	// 同一会话中的数字 ID 1 和字符串 ID "1" 是两个不同的请求
+	writeMessage(t, a, &jsonrpc.Request{ID: mustID(t, float64(1)), Method: "tools/call"})
+	upNumber := upstream.next(t)
+	writeMessage(t, a, &jsonrpc.Request{ID: mustID(t, "1"), Method: "tools/call"})
+	upString := upstream.next(t)
+
+	tests := []struct {
+		params string
+		want   string
+	}{
+		{params: `{"requestId":"1"}`, want: idJSON(t, upString.(*jsonrpc.Request).ID)},
+		{params: `{"requestId":1}`, want: idJSON(t, upNumber.(*jsonrpc.Request).ID)},
+	}
+	for _, tt := range tests {
+		writeMessage(t, a, &jsonrpc.Request{Method: "notifications/cancelled", Params: mustParams(t, tt.params)})
+		if got := paramField(t, upstream.next(t), "requestId"); got != tt.want {
+			t.Errorf("cancel %s: requestId = %s, want %s", tt.params, got, tt.want)
+		}
+	}
+
+	// 响应按类型匹配回原来的下游 ID
+	upstream.send(t, &jsonrpc.Response{ID: upString.(*jsonrpc.Request).ID, Result: json.RawMessage(`{"from":"string"}`)})
+	resp := readMessage(t, a).(*jsonrpc.Response)
+	if resp.ID != mustID(t, "1") || string(resp.Result) != `{"from":"string"}` {
+		t.Errorf("response = %v %s, want string ID 1", resp.ID.Raw(), resp.Result)
+	}
+}
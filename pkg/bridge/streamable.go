@@ -0,0 +1,260 @@
+package bridge
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Copyright 2025 MCP CLI Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// protocolVersionHeader Streamable HTTP 协议版本请求头
+const protocolVersionHeader = "Mcp-Protocol-Version"
+
+// DialStreamable 连接 Streamable HTTP 服务器，返回可原样转发消息的连接。
+//
+// SDK 的客户端连接只有在 ClientSession 完成初始化后才会设置协议版本请求头，
+// 并打开接收服务器主动消息（通知、服务器发起的请求）的 GET 事件流。
+// 原样转发消息时没有 ClientSession，因此这里在转发的 initialize 响应到达后自行完成这两步。
+func DialStreamable(ctx context.Context, transport *mcp.StreamableClientTransport) (mcp.Connection, error) {
+	httpClient := transport.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	base := httpClient.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	versioned := &versionTransport{base: base}
+	client := *httpClient
+	client.Transport = versioned
+
+	t := *transport
+	t.HTTPClient = &client
+	conn, err := t.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	streamCtx, cancel := context.WithCancel(context.Background())
+	c := &streamableConn{
+		Connection: conn,
+		endpoint:   transport.Endpoint,
+		client:     &client,
+		version:    versioned,
+		incoming:   make(chan jsonrpc.Message, 100),
+		ctx:        streamCtx,
+		cancel:     cancel,
+	}
+	go c.readLoop()
+	return c, nil
+}
+
+// streamableConn 在 SDK 的 Streamable HTTP 客户端连接之上补充独立的 GET 事件流
+type streamableConn struct {
+	mcp.Connection
+
+	endpoint string
+	client   *http.Client
+	version  *versionTransport
+	incoming chan jsonrpc.Message
+
+	mu     sync.Mutex
+	initID any // 转发中的 initialize 请求 ID
+
+	ctx       context.Context
+	cancel    context.CancelFunc
+	closeOnce sync.Once
+}
+
+// Read 实现 mcp.Connection 接口，合并请求响应流和 GET 事件流中的消息
+func (c *streamableConn) Read(ctx context.Context) (jsonrpc.Message, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case msg, ok := <-c.incoming:
+		if !ok {
+			return nil, io.EOF
+		}
+		return msg, nil
+	}
+}
+
+// Write 实现 mcp.Connection 接口，记录 initialize 请求以便识别其响应
+func (c *streamableConn) Write(ctx context.Context, msg jsonrpc.Message) error {
+	if req, ok := msg.(*jsonrpc.Request); ok && req.IsCall() && req.Method == "initialize" {
+		c.mu.Lock()
+		c.initID = req.ID.Raw()
+		c.mu.Unlock()
+	}
+	return c.Connection.Write(ctx, msg)
+}
+
+// Close 实现 mcp.Connection 接口
+func (c *streamableConn) Close() error {
+	c.closeOnce.Do(c.cancel)
+	return c.Connection.Close()
+}
+
+// readLoop 读取底层连接的消息，initialize 成功后打开 GET 事件流
+func (c *streamableConn) readLoop() {
+	defer close(c.incoming)
+	defer c.closeOnce.Do(c.cancel)
+
+	for {
+		msg, err := c.Connection.Read(c.ctx)
+		if err != nil {
+			return
+		}
+		if resp, ok := msg.(*jsonrpc.Response); ok && c.isInitResponse(resp) {
+			var result struct {
+				ProtocolVersion string `json:"protocolVersion"`
+			}
+			if json.Unmarshal(resp.Result, &result) == nil {
+				c.version.set(result.ProtocolVersion)
+			}
+			go c.streamLoop()
+		}
+		if !c.push(msg) {
+			return
+		}
+	}
+}
+
+// isInitResponse 判断响应是否对应转发中的 initialize 请求
+func (c *streamableConn) isInitResponse(resp *jsonrpc.Response) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.initID == nil || resp.Error != nil || resp.ID.Raw() != c.initID {
+		return false
+	}
+	c.initID = nil
+	return true
+}
+
+// push 将消息放入读取队列，连接关闭时返回 false
+func (c *streamableConn) push(msg jsonrpc.Message) bool {
+	select {
+	case c.incoming <- msg:
+		return true
+	case <-c.ctx.Done():
+		return false
+	}
+}
+
+// streamLoop 保持 GET 事件流打开，断开后重连。
+// 服务器不支持 GET（405）时直接返回。
+func (c *streamableConn) streamLoop() {
+	failures := 0
+	for c.ctx.Err() == nil && failures < 5 {
+		req, err := http.NewRequestWithContext(c.ctx, http.MethodGet, c.endpoint, nil)
+		if err != nil {
+			return
+		}
+		req.Header.Set("Accept", "text/event-stream")
+		if id := c.SessionID(); id != "" {
+			req.Header.Set("Mcp-Session-Id", id)
+		}
+
+		resp, err := c.client.Do(req)
+		if err == nil && resp.StatusCode == http.StatusMethodNotAllowed {
+			resp.Body.Close()
+			return
+		}
+		if err == nil && resp.StatusCode == http.StatusOK {
+			failures = 0
+			c.readEvents(resp.Body)
+		} else {
+			failures++
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+
+		select {
+		case <-c.ctx.Done():
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// readEvents 解析 SSE 事件流中的 message 事件
+func (c *streamableConn) readEvents(body io.Reader) {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	var event string
+	var data bytes.Buffer
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			if data.Len() > 0 && (event == "" || event == "message") {
+				if msg, err := jsonrpc.DecodeMessage(data.Bytes()); err == nil && !c.push(msg) {
+					return
+				}
+			}
+			event = ""
+			data.Reset()
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			event = value
+		case "data":
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(value)
+		}
+	}
+}
+
+// versionTransport 在初始化完成后为每个请求添加协议版本请求头
+type versionTransport struct {
+	base http.RoundTripper
+
+	mu      sync.Mutex
+	version string
+}
+
+func (t *versionTransport) set(version string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.version = version
+}
+
+func (t *versionTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	version := t.version
+	t.mu.Unlock()
+
+	if version != "" && req.Header.Get(protocolVersionHeader) == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set(protocolVersionHeader, version)
+	}
+	return t.base.RoundTrip(req)
+}
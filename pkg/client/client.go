@@ -2,13 +2,20 @@ package client
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"os/exec"
 	"slices"
+	"sync"
+	"time"
 
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"golang.org/x/oauth2"
 )
 
 // Copyright 2025 MCP CLI Contributors
@@ -25,68 +32,136 @@ import (
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
+// 默认超时，服务器配置中的 connectTimeout、callTimeout 和 shutdownGrace 可以覆盖
+const (
+	DefaultConnectTimeout = 30 * time.Second
+	DefaultCallTimeout    = 30 * time.Second
+	DefaultShutdownGrace  = 5 * time.Second
+)
+
 // MCPClient 统一的 MCP 客户端包装
 type MCPClient struct {
-	client  *mcp.Client
-	session *mcp.ClientSession
+	client      *mcp.Client
+	session     *mcp.ClientSession
+	cancel      context.CancelFunc // 取消连接使用的上下文，Close 时调用
+	callTimeout time.Duration      // 单次请求的超时，为 0 时只受调用方上下文限制
+	serverLog   io.Writer          // stdio 服务器 stderr 的实时输出（可选）
+	stderr      *tailBuffer        // stdio 服务器最近的 stderr 输出，用于错误信息
+
+	mu           sync.Mutex
+	toolsChanged func() // 服务器通知工具列表变化时调用，见 OnToolListChanged
 }
 
+// Options 创建客户端时的可选设置
+type Options struct {
+	// ServerLog 实时接收 stdio 服务器的 stderr（如终端或日志文件）。
+	// 无论是否设置，最近的输出都会保留在内存中，连接和请求失败时附在错误信息里。
+	ServerLog io.Writer
+
+	// LogMessageHandler 接收服务器通过 notifications/message 发送的日志（可选）
+	LogMessageHandler func(params *mcp.LoggingMessageParams)
+}
+
+// LogLevels MCP 日志级别，按严重程度从低到高排列
+var LogLevels = []string{"debug", "info", "notice", "warning", "error", "critical", "alert", "emergency"}
+
 // StdioConfig Stdio 传输配置
 type StdioConfig struct {
-	Command string
-	Args    []string
-	Env     map[string]string
+	Command           string
+	Args              []string
+	Env               map[string]string
+	Dir               string        // 工作目录（可选）
+	ClearEnv          bool          // 不继承当前进程的环境变量，只使用 Env
+	TerminateDuration time.Duration // 关闭时等待进程退出的时间，超时后发送 SIGTERM，为 0 时使用 SDK 默认值
+	Stderr            io.Writer     // 服务器 stderr 的输出（可选），为 nil 时丢弃
 }
 
 // SSEConfig SSE 传输配置
 type SSEConfig struct {
-	Endpoint   string            // SSE 端点 URL
-	HTTPClient *http.Client      // HTTP 客户端（可选）
-	Headers    map[string]string // 自定义请求头
+	Endpoint    string             // SSE 端点 URL
+	HTTPClient  *http.Client       // HTTP 客户端（可选）
+	Headers     map[string]string  // 自定义请求头
+	TLSConfig   *tls.Config        // TLS 设置（可选）
+	TokenSource oauth2.TokenSource // OAuth 令牌来源（可选）
 }
 
 // HTTPConfig HTTP 传输配置
 type HTTPConfig struct {
-	Endpoint   string            // HTTP 端点 URL
-	HTTPClient *http.Client      // HTTP 客户端（可选）
-	MaxRetries int               // 最大重试次数
-	Logger     interface{}       // 日志记录器（可选）
-	Headers    map[string]string // 自定义请求头
+	Endpoint    string             // HTTP 端点 URL
+	HTTPClient  *http.Client       // HTTP 客户端（可选）
+	MaxRetries  int                // 最大重试次数
+	Logger      interface{}        // 日志记录器（可选）
+	Headers     map[string]string  // 自定义请求头
+	TLSConfig   *tls.Config        // TLS 设置（可选）
+	TokenSource oauth2.TokenSource // OAuth 令牌来源（可选）
 }
 
 // NewClient 创建新的 MCP 客户端
 func NewClient(name, version string) *MCPClient {
-	client := mcp.NewClient(&mcp.Implementation{
+	return NewClientWithOptions(name, version, nil)
+}
+
+// NewClientWithOptions 使用可选设置创建 MCP 客户端，opts 可以为 nil
+func NewClientWithOptions(name, version string, opts *Options) *MCPClient {
+	c := &MCPClient{
+		callTimeout: DefaultCallTimeout,
+	}
+	clientOpts := &mcp.ClientOptions{
+		ToolListChangedHandler: func(ctx context.Context, req *mcp.ToolListChangedRequest) {
+			c.mu.Lock()
+			handler := c.toolsChanged
+			c.mu.Unlock()
+			if handler != nil {
+				handler()
+			}
+		},
+	}
+	if opts != nil && opts.LogMessageHandler != nil {
+		handler := opts.LogMessageHandler
+		clientOpts.LoggingMessageHandler = func(ctx context.Context, req *mcp.LoggingMessageRequest) {
+			handler(req.Params)
+		}
+	}
+	c.client = mcp.NewClient(&mcp.Implementation{
 		Name:    name,
 		Version: version,
-	}, nil)
+	}, clientOpts)
 
-	return &MCPClient{
-		client: client,
+	if opts != nil {
+		c.serverLog = opts.ServerLog
 	}
+	return c
+}
+
+// OnToolListChanged 设置服务器发送 notifications/tools/list_changed 时调用的函数，传入 nil 取消。
+// 通知在会话的读取循环中处理，handler 需要向服务器发请求（如重新列出工具）时应在新的 goroutine 中进行。
+func (c *MCPClient) OnToolListChanged(handler func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.toolsChanged = handler
 }
 
 // ConnectStdio 使用 stdio 传输连接到服务器
 func (c *MCPClient) ConnectStdio(ctx context.Context, config *StdioConfig) error {
+	return c.connect(ctx, StdioTransport(config), 0)
+}
+
+// StdioTransport 根据配置创建启动服务器进程的 stdio 传输，
+// 供 ConnectStdio 和需要直接转发 JSON-RPC 消息的场景（如 bridge）共用
+func StdioTransport(config *StdioConfig) *mcp.CommandTransport {
 	cmd := exec.Command(config.Command, config.Args...)
-	if len(config.Env) > 0 {
-		env := slices.Clone(os.Environ()) // Clone to avoid modifying global os.Environ()
-		for k, v := range config.Env {
-			env = append(env, fmt.Sprintf("%s=%s", k, v))
-		}
-		cmd.Env = env
-	} else {
-		cmd.Env = slices.Clone(os.Environ())
+	cmd.Dir = config.Dir
+	env := []string{}
+	if !config.ClearEnv {
+		env = slices.Clone(os.Environ()) // Clone to avoid modifying global os.Environ()
 	}
-
-	transport := &mcp.CommandTransport{Command: cmd}
-	session, err := c.client.Connect(ctx, transport, nil)
-	if err != nil {
-		return fmt.Errorf("failed to connect: %w", err)
+	for k, v := range config.Env {
+		env = append(env, fmt.Sprintf("%s=%s", k, v))
 	}
+	cmd.Env = env
+	cmd.Stderr = config.Stderr
 
-	c.session = session
-	return nil
+	return &mcp.CommandTransport{Command: cmd, TerminateDuration: config.TerminateDuration}
 }
 
 // ConnectSSE 使用 SSE 传输连接到服务器
@@ -94,25 +169,15 @@ func (c *MCPClient) ConnectSSE(ctx context.Context, config *SSEConfig) error {
 	if config.Endpoint == "" {
 		return fmt.Errorf("Endpoint is required for SSE transport")
 	}
-	transport := &mcp.SSEClientTransport{
-		Endpoint: config.Endpoint,
-	}
-	var httpClient *http.Client
-	if config.HTTPClient != nil {
-		httpClient = config.HTTPClient
-	} else if len(config.Headers) > 0 {
-		httpClient = createHTTPClientWithHeaders(config.Headers)
-	} else {
-		httpClient = &http.Client{}
-	}
-	transport.HTTPClient = httpClient
-	session, err := c.client.Connect(ctx, transport, nil)
-	if err != nil {
-		return fmt.Errorf("failed to connect: %w", err)
-	}
+	return c.connect(ctx, SSETransport(config), 0)
+}
 
-	c.session = session
-	return nil
+// SSETransport 根据配置创建 SSE 客户端传输
+func SSETransport(config *SSEConfig) *mcp.SSEClientTransport {
+	return &mcp.SSEClientTransport{
+		Endpoint:   config.Endpoint,
+		HTTPClient: httpClientFor(config.HTTPClient, config.Headers, config.TLSConfig, config.TokenSource),
+	}
 }
 
 // ConnectHTTP 使用 HTTP 传输连接到服务器
@@ -120,53 +185,173 @@ func (c *MCPClient) ConnectHTTP(ctx context.Context, config *HTTPConfig) error {
 	if config.Endpoint == "" {
 		return fmt.Errorf("Endpoint is required for HTTP transport")
 	}
-	transport := &mcp.StreamableClientTransport{
+	return c.connect(ctx, HTTPTransport(config), 0)
+}
+
+// HTTPTransport 根据配置创建 Streamable HTTP 客户端传输
+func HTTPTransport(config *HTTPConfig) *mcp.StreamableClientTransport {
+	return &mcp.StreamableClientTransport{
 		Endpoint:   config.Endpoint,
+		HTTPClient: httpClientFor(config.HTTPClient, config.Headers, config.TLSConfig, config.TokenSource),
 		MaxRetries: config.MaxRetries,
 	}
-	var httpClient *http.Client
-	if config.HTTPClient != nil {
-		httpClient = config.HTTPClient
-	} else if len(config.Headers) > 0 {
-		httpClient = createHTTPClientWithHeaders(config.Headers)
-	} else {
-		httpClient = &http.Client{}
-	}
-	transport.HTTPClient = httpClient
-	session, err := c.client.Connect(ctx, transport, nil)
-	if err != nil {
-		return fmt.Errorf("failed to connect: %w", err)
-	}
-
-	c.session = session
-	return nil
 }
 
-// createHTTPClientWithHeaders 创建一个带有自定义请求头的 HTTP 客户端
-func createHTTPClientWithHeaders(headers map[string]string) *http.Client {
-	return &http.Client{
-		Transport: &headerTransport{headers: headers},
+// httpClientFor 优先使用配置中的 HTTP 客户端，否则按需创建带请求头、TLS 设置和 OAuth 令牌的客户端。
+// 令牌由 oauth2.Transport 在每个请求前获取，过期时自动刷新。
+func httpClientFor(httpClient *http.Client, headers map[string]string, tlsConfig *tls.Config, tokens oauth2.TokenSource) *http.Client {
+	if httpClient != nil {
+		return httpClient
 	}
+
+	var base http.RoundTripper
+	if tlsConfig != nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = tlsConfig
+		base = transport
+	}
+	if len(headers) > 0 {
+		base = &headerTransport{headers: headers, base: base}
+	}
+	if tokens != nil {
+		base = &oauth2.Transport{Source: tokens, Base: base}
+	}
+	return &http.Client{Transport: base}
 }
 
 // headerTransport 自定义 HTTP 传输，用于添加请求头
 type headerTransport struct {
 	headers map[string]string
+	base    http.RoundTripper // 为 nil 时使用 http.DefaultTransport
 }
 
 func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	for k, v := range t.headers {
 		req.Header.Set(k, v)
 	}
+	if t.base != nil {
+		return t.base.RoundTrip(req)
+	}
 	return http.DefaultTransport.RoundTrip(req)
 }
 
-// ListTools 列出所有可用工具
+// connect 建立会话，timeout 为 0 时只受 ctx 限制。
+// SSE 的事件流绑定在连接使用的上下文上，连接成功后上下文不能到期，
+// 因此超时通过在连接完成前取消上下文实现，而不是 context.WithTimeout。
+func (c *MCPClient) connect(ctx context.Context, transport mcp.Transport, timeout time.Duration) error {
+	if command, ok := transport.(*mcp.CommandTransport); ok {
+		c.captureStderr(command)
+	}
+
+	connCtx, cancel := context.WithCancel(ctx)
+	var timer *time.Timer
+	if timeout > 0 {
+		timer = time.AfterFunc(timeout, cancel)
+	}
+
+	session, err := c.client.Connect(connCtx, transport, nil)
+	if timer != nil && !timer.Stop() {
+		if session != nil {
+			session.Close()
+		}
+		cancel()
+		return c.withStderr(fmt.Errorf("failed to connect: timed out after %s: %w", timeout, context.DeadlineExceeded))
+	}
+	if err != nil {
+		cancel()
+		return c.withStderr(fmt.Errorf("failed to connect: %w", err))
+	}
+
+	c.session = session
+	c.cancel = cancel
+	return nil
+}
+
+// captureStderr 将 stdio 服务器的 stderr 接入缓冲区，并复制到原有的输出和 serverLog
+func (c *MCPClient) captureStderr(command *mcp.CommandTransport) {
+	c.stderr = &tailBuffer{}
+	w := &stderrWriter{tail: c.stderr}
+	if command.Command.Stderr != nil {
+		w.copies = append(w.copies, command.Command.Stderr)
+	}
+	if c.serverLog != nil {
+		w.copies = append(w.copies, c.serverLog)
+	}
+	command.Command.Stderr = w
+	// 服务器退出后，其子进程可能仍持有 stderr，不无限等待管道关闭
+	command.Command.WaitDelay = time.Second
+}
+
+// withStderr 在错误后附上 stdio 服务器最近的 stderr 输出
+func (c *MCPClient) withStderr(err error) error {
+	if err == nil || c.stderr == nil {
+		return err
+	}
+	tail := c.stderr.Tail(stderrTailLines)
+	if tail == "" {
+		return err
+	}
+	return &StderrError{Err: err, Stderr: tail}
+}
+
+// requestError 处理请求错误：连接中断（如 stdio 服务器进程退出）时附上服务器最近的 stderr 输出。
+// 服务器正常返回的 JSON-RPC 错误和超时与 stderr 无关，原样返回。
+func (c *MCPClient) requestError(err error) error {
+	if !isConnectionLost(err) {
+		return err
+	}
+	return c.withStderr(err)
+}
+
+// isConnectionLost 判断请求是否因连接中断而失败
+func isConnectionLost(err error) bool {
+	return errors.Is(err, mcp.ErrConnectionClosed) || errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, os.ErrClosed)
+}
+
+// withCallTimeout 为单次请求附加 callTimeout
+func (c *MCPClient) withCallTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.callTimeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, c.callTimeout)
+}
+
+// ListTools 列出所有可用工具（自动处理分页）
 func (c *MCPClient) ListTools(ctx context.Context) (*mcp.ListToolsResult, error) {
 	if c.session == nil {
 		return nil, fmt.Errorf("not connected")
 	}
-	return c.session.ListTools(ctx, nil)
+	ctx, cancel := c.withCallTimeout(ctx)
+	defer cancel()
+
+	result := &mcp.ListToolsResult{}
+	for tool, err := range c.session.Tools(ctx, nil) {
+		if err != nil {
+			return nil, c.requestError(err)
+		}
+		result.Tools = append(result.Tools, tool)
+	}
+	return result, nil
+}
+
+// GetTool 按名称查找工具定义（自动处理分页）
+func (c *MCPClient) GetTool(ctx context.Context, toolName string) (*mcp.Tool, error) {
+	if c.session == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	ctx, cancel := c.withCallTimeout(ctx)
+	defer cancel()
+
+	for tool, err := range c.session.Tools(ctx, nil) {
+		if err != nil {
+			return nil, c.requestError(err)
+		}
+		if tool.Name == toolName {
+			return tool, nil
+		}
+	}
+	return nil, fmt.Errorf("tool not found: %s", toolName)
 }
 
 // CallTool 调用工具
@@ -174,21 +359,147 @@ func (c *MCPClient) CallTool(ctx context.Context, toolName string, args map[stri
 	if c.session == nil {
 		return nil, fmt.Errorf("not connected")
 	}
+	ctx, cancel := c.withCallTimeout(ctx)
+	defer cancel()
 
 	params := &mcp.CallToolParams{
 		Name:      toolName,
 		Arguments: args,
 	}
 
-	return c.session.CallTool(ctx, params)
+	result, err := c.session.CallTool(ctx, params)
+	return result, c.requestError(err)
 }
 
-// Close 关闭连接
+// ListResources 列出所有可用资源（自动处理分页）
+func (c *MCPClient) ListResources(ctx context.Context) (*mcp.ListResourcesResult, error) {
+	if c.session == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	ctx, cancel := c.withCallTimeout(ctx)
+	defer cancel()
+
+	result := &mcp.ListResourcesResult{}
+	for resource, err := range c.session.Resources(ctx, nil) {
+		if err != nil {
+			return nil, c.requestError(err)
+		}
+		result.Resources = append(result.Resources, resource)
+	}
+	return result, nil
+}
+
+// ListResourceTemplates 列出所有资源模板（自动处理分页）。
+// 资源模板是可选功能，服务器不支持（返回 method not found）时视为没有模板。
+func (c *MCPClient) ListResourceTemplates(ctx context.Context) (*mcp.ListResourceTemplatesResult, error) {
+	if c.session == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	ctx, cancel := c.withCallTimeout(ctx)
+	defer cancel()
+
+	result := &mcp.ListResourceTemplatesResult{}
+	for template, err := range c.session.ResourceTemplates(ctx, nil) {
+		if err != nil {
+			var rpcErr *jsonrpc.Error
+			if errors.As(err, &rpcErr) && rpcErr.Code == jsonrpc.CodeMethodNotFound {
+				return &mcp.ListResourceTemplatesResult{}, nil
+			}
+			return nil, c.requestError(err)
+		}
+		result.ResourceTemplates = append(result.ResourceTemplates, template)
+	}
+	return result, nil
+}
+
+// ReadResource 读取指定 URI 的资源
+func (c *MCPClient) ReadResource(ctx context.Context, uri string) (*mcp.ReadResourceResult, error) {
+	if c.session == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	ctx, cancel := c.withCallTimeout(ctx)
+	defer cancel()
+
+	params := &mcp.ReadResourceParams{
+		URI: uri,
+	}
+
+	result, err := c.session.ReadResource(ctx, params)
+	return result, c.requestError(err)
+}
+
+// ListPrompts 列出所有可用提示模板（自动处理分页）
+func (c *MCPClient) ListPrompts(ctx context.Context) (*mcp.ListPromptsResult, error) {
+	if c.session == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	ctx, cancel := c.withCallTimeout(ctx)
+	defer cancel()
+
+	result := &mcp.ListPromptsResult{}
+	for prompt, err := range c.session.Prompts(ctx, nil) {
+		if err != nil {
+			return nil, c.requestError(err)
+		}
+		result.Prompts = append(result.Prompts, prompt)
+	}
+	return result, nil
+}
+
+// GetPrompt 使用给定参数渲染提示模板
+func (c *MCPClient) GetPrompt(ctx context.Context, name string, args map[string]string) (*mcp.GetPromptResult, error) {
+	if c.session == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	ctx, cancel := c.withCallTimeout(ctx)
+	defer cancel()
+
+	params := &mcp.GetPromptParams{
+		Name:      name,
+		Arguments: args,
+	}
+
+	result, err := c.session.GetPrompt(ctx, params)
+	return result, c.requestError(err)
+}
+
+// SetLogLevel 请求服务器只发送不低于 level 的日志，level 取值见 LogLevels。
+// 服务器未声明 logging 能力时返回错误。
+func (c *MCPClient) SetLogLevel(ctx context.Context, level string) error {
+	if c.session == nil {
+		return fmt.Errorf("not connected")
+	}
+	if !slices.Contains(LogLevels, level) {
+		return fmt.Errorf("unknown log level %q", level)
+	}
+	if init := c.session.InitializeResult(); init == nil || init.Capabilities == nil || init.Capabilities.Logging == nil {
+		return fmt.Errorf("server does not support logging")
+	}
+	ctx, cancel := c.withCallTimeout(ctx)
+	defer cancel()
+
+	err := c.session.SetLoggingLevel(ctx, &mcp.SetLoggingLevelParams{Level: mcp.LoggingLevel(level)})
+	return c.requestError(err)
+}
+
+// Close 关闭连接。stdio 服务器先关闭其 stdin 等待自行退出，超过 TerminateDuration 后才会被结束。
 func (c *MCPClient) Close() error {
+	var err error
 	if c.session != nil {
-		return c.session.Close()
+		err = c.session.Close()
 	}
-	return nil
+	if c.cancel != nil {
+		c.cancel()
+	}
+	return err
+}
+
+// ServerInfo 返回服务器在初始化时报告的信息和能力，未连接时返回 nil
+func (c *MCPClient) ServerInfo() *mcp.InitializeResult {
+	if c.session == nil {
+		return nil
+	}
+	return c.session.InitializeResult()
 }
 
 // IsConnected 检查是否已连接
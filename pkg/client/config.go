@@ -0,0 +1,204 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/justinwongcn/go-mcp-cli/pkg/auth"
+	"github.com/justinwongcn/go-mcp-cli/pkg/config"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"golang.org/x/oauth2"
+)
+
+// Copyright 2025 MCP CLI Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// ConfigError 服务器配置本身无法用于连接，如未知的传输方式或无法读取的证书文件
+type ConfigError struct {
+	Server string
+	Err    error
+}
+
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("server %s: %v", e.Server, e.Err)
+}
+
+func (e *ConfigError) Unwrap() error {
+	return e.Err
+}
+
+// ConnectFromConfig 以 clientName 创建客户端并按服务器配置连接，mcp-cli 的命令都经由这里建立客户端连接。
+// server 的要求和返回的错误同 Connect，opts 可以为 nil。
+func ConnectFromConfig(ctx context.Context, clientName string, server *config.ServerConfig, opts *Options) (*MCPClient, error) {
+	cli := NewClientWithOptions(clientName, "1.0.0", opts)
+	if err := cli.Connect(ctx, server); err != nil {
+		return nil, err
+	}
+	return cli, nil
+}
+
+// Connect 按服务器配置选择传输方式并连接，connectTimeout 限制连接时间，callTimeout 用于之后的每个请求。
+// server 应为已展开变量和 secret 引用的配置，见 config.ConfigManager.ResolvedServer。
+func (c *MCPClient) Connect(ctx context.Context, server *config.ServerConfig) error {
+	connectTimeout, err := server.ConnectTimeout.Value(DefaultConnectTimeout)
+	if err != nil {
+		return &ConfigError{Server: server.Name, Err: fmt.Errorf("connectTimeout: %w", err)}
+	}
+	callTimeout, err := server.CallTimeout.Value(DefaultCallTimeout)
+	if err != nil {
+		return &ConfigError{Server: server.Name, Err: fmt.Errorf("callTimeout: %w", err)}
+	}
+	transport, err := TransportFromConfig(server)
+	if err != nil {
+		return err
+	}
+
+	if err := c.connect(ctx, transport, connectTimeout); err != nil {
+		return err
+	}
+	c.callTimeout = callTimeout
+	return nil
+}
+
+// TransportFromConfig 根据服务器配置创建传输，所有命令都经由这里把配置字段映射到传输上：
+//   - stdio：command、args、env、cwd、inheritEnv、shutdownGrace
+//   - sse：url、headers、tls，配置了 auth 时附带 mcp-cli login 保存的令牌
+//   - http：与 sse 相同，另外使用 maxRetries
+//
+// 配置了 auth 但尚未登录时返回的错误包装了 auth.ErrNotLoggedIn。
+// stdio 传输每次连接都会启动新进程，需要多个连接时应分别调用。
+func TransportFromConfig(server *config.ServerConfig) (mcp.Transport, error) {
+	switch server.Transport {
+	case "stdio":
+		if server.Command == "" {
+			return nil, &ConfigError{Server: server.Name, Err: fmt.Errorf("command is required for stdio servers")}
+		}
+		grace, err := server.ShutdownGrace.Value(DefaultShutdownGrace)
+		if err != nil {
+			return nil, &ConfigError{Server: server.Name, Err: fmt.Errorf("shutdownGrace: %w", err)}
+		}
+		return StdioTransport(&StdioConfig{
+			Command:           server.Command,
+			Args:              server.Args,
+			Env:               server.Env,
+			Dir:               server.Cwd,
+			ClearEnv:          server.InheritEnv != nil && !*server.InheritEnv,
+			TerminateDuration: grace,
+		}), nil
+
+	case "sse", "http":
+		if server.URL == "" {
+			return nil, &ConfigError{Server: server.Name, Err: fmt.Errorf("url is required for %s servers", server.Transport)}
+		}
+		tlsConfig, err := TLSClientConfig(server.TLS)
+		if err != nil {
+			return nil, &ConfigError{Server: server.Name, Err: err}
+		}
+		tokens, err := tokenSource(server, tlsConfig)
+		if err != nil {
+			return nil, err
+		}
+
+		if server.Transport == "sse" {
+			return SSETransport(&SSEConfig{
+				Endpoint:    server.URL,
+				Headers:     server.Headers,
+				TLSConfig:   tlsConfig,
+				TokenSource: tokens,
+			}), nil
+		}
+		return HTTPTransport(&HTTPConfig{
+			Endpoint:    server.URL,
+			MaxRetries:  server.MaxRetries,
+			Headers:     server.Headers,
+			TLSConfig:   tlsConfig,
+			TokenSource: tokens,
+		}), nil
+
+	default:
+		return nil, &ConfigError{Server: server.Name, Err: fmt.Errorf("unknown transport type: %s", server.Transport)}
+	}
+}
+
+// tokenSource 返回配置了 auth 的服务器的令牌来源，未配置时返回 nil。
+// 刷新令牌的请求使用与服务器连接相同的 TLS 设置。
+func tokenSource(server *config.ServerConfig, tlsConfig *tls.Config) (oauth2.TokenSource, error) {
+	if server.Auth == nil {
+		return nil, nil
+	}
+
+	store, err := auth.NewStore("")
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := store.TokenSource(server.URL, httpClientFor(nil, nil, tlsConfig, nil))
+	if err != nil {
+		return nil, fmt.Errorf("server %s: %w", server.Name, err)
+	}
+	return tokens, nil
+}
+
+// OAuthHTTPClient 返回 OAuth 发现、注册和换取令牌使用的 HTTP 客户端，使用服务器的 TLS 设置
+func OAuthHTTPClient(settings *config.TLSConfig) (*http.Client, error) {
+	tlsConfig, err := TLSClientConfig(settings)
+	if err != nil {
+		return nil, err
+	}
+	return httpClientFor(nil, nil, tlsConfig, nil), nil
+}
+
+// TLSClientConfig 根据 TLS 设置加载证书，未配置时返回 nil（使用系统默认设置）
+func TLSClientConfig(settings *config.TLSConfig) (*tls.Config, error) {
+	if settings == nil {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		ServerName:         settings.ServerName,
+		InsecureSkipVerify: settings.InsecureSkipVerify,
+	}
+
+	if settings.CAFile != "" {
+		pem, err := os.ReadFile(settings.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA file %s", settings.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if settings.CertFile != "" || settings.KeyFile != "" {
+		if settings.CertFile == "" || settings.KeyFile == "" {
+			return nil, fmt.Errorf("tls certFile and keyFile must be set together")
+		}
+		cert, err := tls.LoadX509KeyPair(settings.CertFile, settings.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
@@ -0,0 +1,61 @@
+package client
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/justinwongcn/go-mcp-cli/pkg/config"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Copyright 2025 MCP CLI Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+func TestTransportFromConfigShutdownGrace(t *testing.T) {
+	tests := []struct {
+		name    string
+		grace   config.Duration
+		want    time.Duration
+		wantErr bool
+	}{
+		{name: "default", want: DefaultShutdownGrace},
+		{name: "configured", grace: "2s", want: 2 * time.Second},
+		{name: "invalid", grace: "soon", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport, err := TransportFromConfig(&config.ServerConfig{Name: "s", Transport: "stdio", Command: "x", ShutdownGrace: tt.grace})
+			if tt.wantErr {
+				var configErr *ConfigError
+				if !errors.As(err, &configErr) {
+					t.Errorf("TransportFromConfig() error = %v, want ConfigError", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			command, ok := transport.(*mcp.CommandTransport)
+			if !ok {
+				t.Fatalf("TransportFromConfig() = %T, want *mcp.CommandTransport", transport)
+			}
+			if command.TerminateDuration != tt.want {
+				t.Errorf("TerminateDuration = %v, want %v", command.TerminateDuration, tt.want)
+			}
+		})
+	}
+}
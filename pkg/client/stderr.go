@@ -0,0 +1,104 @@
+package client
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// Copyright 2025 MCP CLI Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+const (
+	// stderrBufferSize 为每个 stdio 服务器保留的 stderr 字节数
+	stderrBufferSize = 16 << 10
+
+	// stderrTailLines 错误信息中附带的 stderr 行数
+	stderrTailLines = 20
+)
+
+// StderrError 附带 stdio 服务器最近 stderr 输出的错误，服务器启动失败或崩溃时原因通常在这里
+type StderrError struct {
+	Err    error
+	Stderr string // 最后若干行，不含末尾换行
+}
+
+func (e *StderrError) Error() string {
+	return fmt.Sprintf("%v\nserver stderr:\n  | %s", e.Err, strings.ReplaceAll(e.Stderr, "\n", "\n  | "))
+}
+
+func (e *StderrError) Unwrap() error {
+	return e.Err
+}
+
+// tailBuffer 保留最近写入的 stderrBufferSize 字节，可并发写入和读取
+type tailBuffer struct {
+	mu        sync.Mutex
+	data      []byte
+	truncated bool // 是否丢弃过较早的输出
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.data = append(b.data, p...)
+	if over := len(b.data) - stderrBufferSize; over > 0 {
+		b.data = append(b.data[:0], b.data[over:]...)
+		b.truncated = true
+	}
+	return len(p), nil
+}
+
+// Tail 返回最后 n 行（忽略空行），缓冲区被截断时丢弃不完整的第一行
+func (b *tailBuffer) Tail(n int) string {
+	b.mu.Lock()
+	data := bytes.Clone(b.data)
+	truncated := b.truncated
+	b.mu.Unlock()
+
+	if truncated {
+		if i := bytes.IndexByte(data, '\n'); i >= 0 {
+			data = data[i+1:]
+		}
+	}
+
+	var lines []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimRight(line, "\r"); strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// stderrWriter 将服务器的 stderr 写入缓冲区并复制到其他输出。
+// 复制失败（如日志文件所在磁盘已满）时忽略错误，避免服务器因 stderr 管道断开而退出。
+type stderrWriter struct {
+	tail   *tailBuffer
+	copies []io.Writer
+}
+
+func (w *stderrWriter) Write(p []byte) (int, error) {
+	w.tail.Write(p)
+	for _, c := range w.copies {
+		c.Write(p)
+	}
+	return len(p), nil
+}
@@ -0,0 +1,112 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Copyright 2025 MCP CLI Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+func TestTailBuffer(t *testing.T) {
+	full := strings.Repeat("x", stderrBufferSize-1) + "\n"
+
+	tests := []struct {
+		name   string
+		writes []string
+		n      int
+		want   string
+	}{
+		{name: "empty", n: 3, want: ""},
+		{name: "last lines", writes: []string{"a\nb\n", "c\nd\n"}, n: 2, want: "c\nd"},
+		{name: "partial writes", writes: []string{"hel", "lo\nwor", "ld"}, n: 5, want: "hello\nworld"},
+		{name: "blank lines and CRLF", writes: []string{"a\r\n\n  \nb\r\n"}, n: 5, want: "a\nb"},
+		{name: "exactly full", writes: []string{full}, n: 1, want: strings.TrimSuffix(full, "\n")},
+		{name: "truncated drops partial line", writes: []string{"first line\n", full, "last\n"}, n: 5, want: "last"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &tailBuffer{}
+			for _, w := range tt.writes {
+				if n, err := b.Write([]byte(w)); err != nil || n != len(w) {
+					t.Fatalf("Write() = %d, %v", n, err)
+				}
+			}
+			if got := b.Tail(tt.n); got != tt.want {
+				if len(got) > 40 {
+					got = got[:40] + "..."
+				}
+				t.Errorf("Tail(%d) = %q, want %d bytes", tt.n, got, len(tt.want))
+			}
+			if len(b.data) > stderrBufferSize {
+				t.Errorf("buffer holds %d bytes, want at most %d", len(b.data), stderrBufferSize)
+			}
+		})
+	}
+}
+
+func TestStderrError(t *testing.T) {
+	err := &StderrError{Err: io.EOF, Stderr: "line 1\nline 2"}
+	want := "EOF\nserver stderr:\n  | line 1\n  | line 2"
+	if err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+	if !errors.Is(err, io.EOF) {
+		t.Error("StderrError does not unwrap to the original error")
+	}
+}
+
+func TestRequestErrorAttachesStderr(t *testing.T) {
+	c := &MCPClient{stderr: &tailBuffer{}}
+	c.stderr.Write([]byte("panic: boom\n"))
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStderr bool
+	}{
+		{name: "no error", err: nil},
+		{name: "server exited", err: fmt.Errorf("calling %q: %w", "tools/call", io.EOF), wantStderr: true},
+		{name: "connection closed", err: fmt.Errorf("%w: calling %q", mcp.ErrConnectionClosed, "tools/list"), wantStderr: true},
+		{name: "json-rpc error", err: fmt.Errorf("calling %q: %w", "tools/call", &jsonrpc.Error{Code: jsonrpc.CodeInvalidParams, Message: "bad args"})},
+		{name: "timeout", err: fmt.Errorf("calling %q: %w", "tools/call", context.DeadlineExceeded)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := c.requestError(tt.err)
+			var stderrErr *StderrError
+			if got := errors.As(err, &stderrErr); got != tt.wantStderr {
+				t.Errorf("requestError(%v) = %v, want stderr attached = %v", tt.err, err, tt.wantStderr)
+			}
+			if !errors.Is(err, tt.err) {
+				t.Errorf("requestError(%v) does not wrap the original error", tt.err)
+			}
+		})
+	}
+
+	// 没有捕获 stderr（非 stdio 服务器）时原样返回
+	remote := &MCPClient{}
+	if err := remote.requestError(io.EOF); err != io.EOF {
+		t.Errorf("requestError() without stderr = %v, want the original error", err)
+	}
+}
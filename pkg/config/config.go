@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 )
 
 // Copyright 2025 MCP CLI Contributors
@@ -24,6 +26,7 @@ import (
 
 // Config 总体配置结构（go-mcp-cli 格式）
 type Config struct {
+	Schema  string                   `json:"$schema,omitempty"` // JSON Schema 地址，供编辑器补全和校验
 	Version string                   `json:"version"`
 	Servers map[string]*ServerConfig `json:"servers"`
 }
@@ -35,6 +38,7 @@ type ClaudeDesktopConfig struct {
 
 // ClaudeServerConfig Claude Desktop 服务器配置
 type ClaudeServerConfig struct {
+	Type    string            `json:"type,omitempty"` // 远程服务器的传输方式：sse 或 http
 	Command string            `json:"command,omitempty"`
 	Args    []string          `json:"args,omitempty"`
 	Env     map[string]string `json:"env,omitempty"`
@@ -52,76 +56,214 @@ type ServerConfig struct {
 	URL        string            `json:"url,omitempty"`
 	Headers    map[string]string `json:"headers,omitempty"`
 	MaxRetries int               `json:"maxRetries,omitempty"`
+	Auth       *AuthConfig       `json:"auth,omitempty"`
+	TLS        *TLSConfig        `json:"tls,omitempty"`
+
+	ConnectTimeout Duration `json:"connectTimeout,omitempty"` // 建立连接（含初始化握手）的超时，默认 30s
+	CallTimeout    Duration `json:"callTimeout,omitempty"`    // 单次请求（调用工具、读取资源等）的超时，默认 30s，"0" 表示不限制
+
+	// 以下字段只用于 stdio 服务器
+	Cwd           string   `json:"cwd,omitempty"`           // 服务器进程的工作目录，默认为 mcp-cli 的当前目录
+	InheritEnv    *bool    `json:"inheritEnv,omitempty"`    // 为 false 时服务器进程不继承 mcp-cli 的环境变量，只获得 env 中的变量
+	ShutdownGrace Duration `json:"shutdownGrace,omitempty"` // 关闭连接时等待进程自行退出的时间，超时后先发送 SIGTERM 再强制结束，默认 5s
+}
+
+// Duration 时长，使用 Go 的时长写法，如 "500ms"、"10s"、"2m"
+type Duration string
+
+// Value 解析时长，为空时返回 fallback
+func (d Duration) Value(fallback time.Duration) (time.Duration, error) {
+	if d == "" {
+		return fallback, nil
+	}
+	value, err := time.ParseDuration(string(d))
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q (use values like 500ms, 10s or 2m)", d)
+	}
+	if value < 0 {
+		return 0, fmt.Errorf("duration %q must not be negative", d)
+	}
+	return value, nil
+}
+
+// AuthConfig OAuth 授权配置，存在时 SSE/HTTP 连接使用 mcp-cli login 获取的令牌
+type AuthConfig struct {
+	ClientID            string   `json:"clientId,omitempty"`            // 预注册的客户端 ID，为空时使用动态客户端注册
+	ClientSecret        string   `json:"clientSecret,omitempty"`        // 预注册的客户端密钥（可选）
+	Scopes              []string `json:"scopes,omitempty"`              // 申请的权限范围
+	AuthorizationServer string   `json:"authorizationServer,omitempty"` // 授权服务器地址，为空时自动发现
+	RedirectPort        int      `json:"redirectPort,omitempty"`        // 本地回调端口，为 0 时随机选择
 }
 
-// ConfigManager 配置管理器
+// TLSConfig SSE/HTTP 连接的 TLS 设置，证书和私钥均为 PEM 文件
+type TLSConfig struct {
+	CAFile             string `json:"caFile,omitempty"`             // 额外信任的 CA 证书，用于自签名或内部 CA 签发的服务器证书
+	CertFile           string `json:"certFile,omitempty"`           // 客户端证书，用于双向 TLS
+	KeyFile            string `json:"keyFile,omitempty"`            // 客户端证书的私钥
+	ServerName         string `json:"serverName,omitempty"`         // 校验服务器证书时使用的主机名，默认取 URL 中的主机
+	InsecureSkipVerify bool   `json:"insecureSkipVerify,omitempty"` // 不校验服务器证书，仅用于测试
+}
+
+// 配置来源，按优先级从低到高排列
+const (
+	OriginUser     = "user"     // 用户级配置：$XDG_CONFIG_HOME/mcp-cli/config.json
+	OriginProject  = "project"  // 项目配置：从当前目录向上查找到的最近的 .mcp-cli/config.json
+	OriginExplicit = "explicit" // 显式指定：--config 参数或 MCP_CLI_CONFIG 环境变量
+)
+
+// ConfigEnv 指定配置文件路径的环境变量
+const ConfigEnv = "MCP_CLI_CONFIG"
+
+// layer 一个配置文件层
+type layer struct {
+	origin string
+	path   string
+	format string // 文件的格式，其他宿主的配置只读
+	config *Config
+}
+
+// ConfigManager 配置管理器。
+//
+// 用户级、项目和显式指定的配置文件依次叠加，同名服务器以优先级高的为准。
+// 新服务器写入优先级最高的层（没有项目配置时在当前目录创建 .mcp-cli/config.json），
+// 修改和删除已有服务器时写回它所在的层。
 type ConfigManager struct {
-	configPath string
-	config     *Config
+	configPath string // 新服务器写入的配置文件
+	layers     []*layer
+
+	servers map[string]*ServerConfig // 合并后的原始配置
+	origins map[string]*layer        // 服务器 -> 所在层
+
+	// 加载时展开 ${...} 引用后的服务器配置，原始配置保持不变以便写回
+	resolved   map[string]*ServerConfig
+	unresolved map[string][]string
 }
 
-// NewConfigManager 创建配置管理器
+// NewConfigManager 创建配置管理器。
+// configPath 非空时作为显式指定的配置文件，否则读取 MCP_CLI_CONFIG 环境变量。
 func NewConfigManager(configPath ...string) (*ConfigManager, error) {
-	var path string
-	if len(configPath) > 0 {
-		path = configPath[0]
-	} else {
-		cwd, err := os.Getwd()
-		if err != nil {
-			return nil, fmt.Errorf("failed to get current directory: %w", err)
-		}
-		path = filepath.Join(cwd, ".mcp-cli", "config.json")
+	explicit := os.Getenv(ConfigEnv)
+	if len(configPath) > 0 && configPath[0] != "" {
+		explicit = configPath[0]
 	}
 
-	// 确保配置目录存在
-	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
-		return nil, fmt.Errorf("failed to create config directory: %w", err)
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current directory: %w", err)
 	}
 
-	cm := &ConfigManager{configPath: path}
-	if err := cm.load(); err != nil && !os.IsNotExist(err) {
-		return nil, err
+	var candidates []*layer
+	if dir, err := UserConfigDir(); err == nil {
+		candidates = append(candidates, &layer{origin: OriginUser, path: filepath.Join(dir, "config.json")})
+	}
+	projectPath := findProjectConfig(cwd)
+	if projectPath != "" {
+		candidates = append(candidates, &layer{origin: OriginProject, path: projectPath})
+	}
+	if explicit != "" {
+		path, err := filepath.Abs(explicit)
+		if err != nil {
+			return nil, fmt.Errorf("invalid config path %s: %w", explicit, err)
+		}
+		candidates = append(candidates, &layer{origin: OriginExplicit, path: path})
 	}
 
-	if cm.config == nil {
-		cm.config = &Config{
-			Version: "1.0.0",
-			Servers: make(map[string]*ServerConfig),
+	cm := &ConfigManager{}
+	seen := make(map[string]bool)
+	for _, l := range candidates {
+		if seen[l.path] {
+			continue
+		}
+		seen[l.path] = true
+
+		// 支持 ParseHostConfig 识别的所有格式，其他宿主的格式会转换为 go-mcp-cli 格式
+		imported, err := LoadHostConfig(l.path)
+		switch {
+		case err == nil:
+			l.format, l.config = imported.Format, imported.Config
+		case os.IsNotExist(err) && l.origin == OriginExplicit:
+			// 显式指定的文件不存在时仍作为写入目标；其他层不存在时跳过
+			l.format, l.config = FormatGoMCPCLI, newConfig()
+		case os.IsNotExist(err):
+			continue
+		default:
+			return nil, fmt.Errorf("%s: %w", l.path, err)
 		}
+		cm.layers = append(cm.layers, l)
+	}
+
+	switch {
+	case explicit != "" || projectPath != "":
+		cm.configPath = cm.layers[len(cm.layers)-1].path
+	default:
+		cm.configPath = filepath.Join(cwd, ".mcp-cli", "config.json")
+		cm.layers = append(cm.layers, &layer{origin: OriginProject, path: cm.configPath, format: FormatGoMCPCLI, config: newConfig()})
 	}
 
+	cm.merge()
 	return cm, nil
 }
 
-// load 加载配置文件
-func (cm *ConfigManager) load() error {
-	data, err := os.ReadFile(cm.configPath)
-	if err != nil {
-		return err
+// newConfig 创建空配置
+func newConfig() *Config {
+	return &Config{
+		Version: "1.0.0",
+		Servers: make(map[string]*ServerConfig),
 	}
+}
 
-	// 尝试解析为 go-mcp-cli 格式
-	var config Config
-	if err := json.Unmarshal(data, &config); err == nil && config.Servers != nil {
-		cm.config = &config
-		return nil
+// UserConfigDir 返回 mcp-cli 的用户级配置目录，优先使用 $XDG_CONFIG_HOME。
+// 用户配置、令牌、密钥存储和 shell 历史都保存在这里。
+func UserConfigDir() (string, error) {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "mcp-cli"), nil
 	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "mcp-cli"), nil
+}
 
-	// 尝试解析为 Claude Desktop 格式
-	var claudeConfig ClaudeDesktopConfig
-	if err := json.Unmarshal(data, &claudeConfig); err == nil && claudeConfig.MCPServers != nil {
-		// 转换为 go-mcp-cli 格式
-		cm.config = &Config{
-			Version: "1.0.0",
-			Servers: make(map[string]*ServerConfig),
+// findProjectConfig 从 dir 开始向上查找最近的 .mcp-cli/config.json，找不到时返回空字符串
+func findProjectConfig(dir string) string {
+	for {
+		path := filepath.Join(dir, ".mcp-cli", "config.json")
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
 		}
-		for name, server := range claudeConfig.MCPServers {
-			cm.config.Servers[name] = convertClaudeServer(name, server)
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
 		}
-		return nil
+		dir = parent
 	}
+}
 
-	return fmt.Errorf("unsupported config format")
+// merge 合并各层配置并展开引用
+func (cm *ConfigManager) merge() {
+	cm.servers = make(map[string]*ServerConfig)
+	cm.origins = make(map[string]*layer)
+	for _, l := range cm.layers {
+		for name, server := range l.config.Servers {
+			cm.servers[name] = server
+			cm.origins[name] = l
+		}
+	}
+	cm.interpolate()
+}
+
+// interpolate 展开所有服务器配置中的引用
+func (cm *ConfigManager) interpolate() {
+	cm.resolved = make(map[string]*ServerConfig, len(cm.servers))
+	cm.unresolved = make(map[string][]string)
+	for name, server := range cm.servers {
+		resolved, unresolved := interpolateServer(server)
+		cm.resolved[name] = resolved
+		if len(unresolved) > 0 {
+			cm.unresolved[name] = unresolved
+		}
+	}
 }
 
 // LoadClaudeDesktopConfig 从 Claude Desktop 配置文件加载
@@ -161,6 +303,8 @@ func convertClaudeServer(name string, server *ClaudeServerConfig) *ServerConfig
 	// 自动检测传输类型
 	if server.Command != "" {
 		config.Transport = "stdio"
+	} else if server.Type == "sse" || server.Type == "http" {
+		config.Transport = server.Type
 	} else if server.URL != "" {
 		config.Transport = detectTransportType(server.URL)
 	}
@@ -178,56 +322,158 @@ func detectTransportType(url string) string {
 	return "http"
 }
 
-// save 保存配置文件
-func (cm *ConfigManager) save() error {
-	data, err := json.MarshalIndent(cm.config, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal config: %w", err)
+// targetLayer 返回服务器应写入的配置层：已有服务器写回所在层，新服务器写入 configPath
+func (cm *ConfigManager) targetLayer(name string) *layer {
+	if l, exists := cm.origins[name]; exists {
+		return l
 	}
-
-	return os.WriteFile(cm.configPath, data, 0644)
+	for _, l := range cm.layers {
+		if l.path == cm.configPath {
+			return l
+		}
+	}
+	return cm.layers[len(cm.layers)-1]
 }
 
-// AddServer 添加服务器配置
+// AddServer 添加或替换服务器配置
 func (cm *ConfigManager) AddServer(name string, config *ServerConfig) error {
-	if cm.config.Servers == nil {
-		cm.config.Servers = make(map[string]*ServerConfig)
+	return cm.update(cm.targetLayer(name), func(c *Config) error {
+		c.Servers[name] = config
+		return nil
+	})
+}
+
+// UpdateServer 修改已有的服务器配置。mutate 在文件锁内执行，收到的是重新读取的最新配置的副本，
+// 返回 nil 时副本写回；并发修改同一服务器的不同字段不会互相覆盖。
+func (cm *ConfigManager) UpdateServer(name string, mutate func(server *ServerConfig) error) error {
+	l, exists := cm.origins[name]
+	if !exists {
+		return fmt.Errorf("server not found: %s", name)
+	}
+	return cm.update(l, func(c *Config) error {
+		server, exists := c.Servers[name]
+		if !exists {
+			return fmt.Errorf("server not found: %s", name)
+		}
+		updated := server.Clone()
+		if err := mutate(updated); err != nil {
+			return err
+		}
+		c.Servers[name] = updated
+		return nil
+	})
+}
+
+// RemoveServer 移除服务器配置（从它所在的配置层中删除），服务器不存在时返回 false
+func (cm *ConfigManager) RemoveServer(name string) (bool, error) {
+	l, exists := cm.origins[name]
+	if !exists {
+		return false, nil
+	}
+	removed := false
+	err := cm.update(l, func(c *Config) error {
+		_, removed = c.Servers[name]
+		delete(c.Servers, name)
+		return nil
+	})
+	return removed, err
+}
+
+// RenameServer 重命名服务器，配置保留在原来的配置文件中
+func (cm *ConfigManager) RenameServer(oldName, newName string) error {
+	l, exists := cm.origins[oldName]
+	if !exists {
+		return fmt.Errorf("server not found: %s", oldName)
 	}
-	cm.config.Servers[name] = config
-	return cm.save()
+	if _, exists := cm.servers[newName]; exists {
+		return fmt.Errorf("server already exists: %s", newName)
+	}
+
+	return cm.update(l, func(c *Config) error {
+		server, exists := c.Servers[oldName]
+		if !exists {
+			return fmt.Errorf("server not found: %s", oldName)
+		}
+		if _, exists := c.Servers[newName]; exists {
+			return fmt.Errorf("server already exists: %s", newName)
+		}
+		delete(c.Servers, oldName)
+		server.Name = newName
+		c.Servers[newName] = server
+		return nil
+	})
 }
 
-// RemoveServer 移除服务器配置
-func (cm *ConfigManager) RemoveServer(name string) bool {
-	if _, exists := cm.config.Servers[name]; !exists {
-		return false
+// CopyServer 复制服务器配置，副本写入源服务器所在的配置文件
+func (cm *ConfigManager) CopyServer(src, dst string) error {
+	l, exists := cm.origins[src]
+	if !exists {
+		return fmt.Errorf("server not found: %s", src)
+	}
+	if _, exists := cm.servers[dst]; exists {
+		return fmt.Errorf("server already exists: %s", dst)
 	}
-	delete(cm.config.Servers, name)
-	cm.save()
-	return true
+
+	return cm.update(l, func(c *Config) error {
+		server, exists := c.Servers[src]
+		if !exists {
+			return fmt.Errorf("server not found: %s", src)
+		}
+		if _, exists := c.Servers[dst]; exists {
+			return fmt.Errorf("server already exists: %s", dst)
+		}
+		server = server.Clone()
+		server.Name = dst
+		c.Servers[dst] = server
+		return nil
+	})
+}
+
+// ConfigPath 返回新服务器写入的配置文件路径
+func (cm *ConfigManager) ConfigPath() string {
+	return cm.configPath
+}
+
+// ServerOrigin 返回服务器配置的来源（user、project 或 explicit）及所在文件
+func (cm *ConfigManager) ServerOrigin(name string) (origin, path string) {
+	l, exists := cm.origins[name]
+	if !exists {
+		return "", ""
+	}
+	return l.origin, l.path
 }
 
-// GetServer 获取服务器配置
+// GetServer 获取服务器配置（未展开引用的原始配置，修改后可直接保存）
 func (cm *ConfigManager) GetServer(name string) *ServerConfig {
-	return cm.config.Servers[name]
+	return cm.servers[name]
+}
+
+// ResolvedServer 获取展开了 ${VAR}、${VAR:-default} 和 ${file:/path} 引用的服务器配置，
+// 服务器不存在时返回 nil。存在无法解析的引用时返回 *UnresolvedError。
+func (cm *ConfigManager) ResolvedServer(name string) (*ServerConfig, error) {
+	if refs := cm.unresolved[name]; len(refs) > 0 {
+		return nil, &UnresolvedError{Server: name, References: refs}
+	}
+	return cm.resolved[name], nil
 }
 
 // ListServers 列出所有服务器
 func (cm *ConfigManager) ListServers() map[string]*ServerConfig {
-	return cm.config.Servers
+	return cm.servers
 }
 
-// GetServerNames 获取所有服务器名称
+// GetServerNames 获取所有服务器名称（按名称排序）
 func (cm *ConfigManager) GetServerNames() []string {
-	names := make([]string, 0, len(cm.config.Servers))
-	for name := range cm.config.Servers {
+	names := make([]string, 0, len(cm.servers))
+	for name := range cm.servers {
 		names = append(names, name)
 	}
+	sort.Strings(names)
 	return names
 }
 
 // ServerExists 检查服务器是否存在
 func (cm *ConfigManager) ServerExists(name string) bool {
-	_, exists := cm.config.Servers[name]
+	_, exists := cm.servers[name]
 	return exists
 }
@@ -0,0 +1,218 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// Copyright 2025 MCP CLI Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// isolateConfig 让测试使用临时的用户配置目录和工作目录，返回临时目录
+func isolateConfig(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(root, "xdg"))
+	t.Setenv(ConfigEnv, "")
+
+	work := filepath.Join(root, "work")
+	if err := os.MkdirAll(work, 0755); err != nil {
+		t.Fatal(err)
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(work); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(cwd) })
+	return root
+}
+
+// writeFile 写入测试文件，必要时创建目录
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// loadServers 读取配置文件中的服务器
+func loadServers(t *testing.T, path string) map[string]*ServerConfig {
+	t.Helper()
+	imported, err := LoadHostConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return imported.Config.Servers
+}
+
+func TestConfigLayers(t *testing.T) {
+	const (
+		userConfig     = `{"version": "1.0.0", "servers": {"a": {"transport": "stdio", "command": "user"}, "b": {"transport": "stdio", "command": "user"}}}`
+		projectConfig  = `{"version": "1.0.0", "servers": {"b": {"transport": "stdio", "command": "project"}, "c": {"transport": "stdio", "command": "project"}}}`
+		explicitConfig = `{"version": "1.0.0", "servers": {"c": {"transport": "stdio", "command": "explicit"}, "d": {"transport": "stdio", "command": "explicit"}}}`
+	)
+
+	tests := []struct {
+		name        string
+		user        string
+		project     string
+		explicit    string
+		explicitEnv bool // 通过 MCP_CLI_CONFIG 而不是参数指定
+		wantOrigins map[string]string
+		wantTarget  string // 新服务器写入的文件，相对于临时目录
+	}{
+		{
+			name:        "user only",
+			user:        userConfig,
+			wantOrigins: map[string]string{"a": OriginUser, "b": OriginUser},
+			wantTarget:  "work/sub/.mcp-cli/config.json",
+		},
+		{
+			name:        "project overrides user",
+			user:        userConfig,
+			project:     projectConfig,
+			wantOrigins: map[string]string{"a": OriginUser, "b": OriginProject, "c": OriginProject},
+			wantTarget:  "work/.mcp-cli/config.json",
+		},
+		{
+			name:        "explicit overrides project",
+			user:        userConfig,
+			project:     projectConfig,
+			explicit:    explicitConfig,
+			wantOrigins: map[string]string{"a": OriginUser, "b": OriginProject, "c": OriginExplicit, "d": OriginExplicit},
+			wantTarget:  "explicit.json",
+		},
+		{
+			name:        "explicit from environment",
+			project:     projectConfig,
+			explicit:    explicitConfig,
+			explicitEnv: true,
+			wantOrigins: map[string]string{"b": OriginProject, "c": OriginExplicit, "d": OriginExplicit},
+			wantTarget:  "explicit.json",
+		},
+		{
+			name:        "missing explicit file is the write target",
+			user:        userConfig,
+			project:     projectConfig,
+			explicitEnv: true,
+			wantOrigins: map[string]string{"a": OriginUser, "b": OriginProject, "c": OriginProject},
+			wantTarget:  "explicit.json",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := isolateConfig(t)
+			paths := map[string]string{
+				OriginUser:     filepath.Join(root, "xdg", "mcp-cli", "config.json"),
+				OriginProject:  filepath.Join(root, "work", ".mcp-cli", "config.json"),
+				OriginExplicit: filepath.Join(root, "explicit.json"),
+			}
+			for origin, content := range map[string]string{OriginUser: tt.user, OriginProject: tt.project, OriginExplicit: tt.explicit} {
+				if content != "" {
+					writeFile(t, paths[origin], content)
+				}
+			}
+			// 项目配置从当前目录向上查找
+			sub := filepath.Join(root, "work", "sub")
+			if err := os.MkdirAll(sub, 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Chdir(sub); err != nil {
+				t.Fatal(err)
+			}
+
+			var args []string
+			if tt.explicitEnv {
+				t.Setenv(ConfigEnv, paths[OriginExplicit])
+			} else if tt.explicit != "" {
+				args = append(args, paths[OriginExplicit])
+			}
+			cm, err := NewConfigManager(args...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			origins := make(map[string]string)
+			for _, name := range cm.GetServerNames() {
+				origin, path := cm.ServerOrigin(name)
+				origins[name] = origin
+				if path != paths[origin] {
+					t.Errorf("ServerOrigin(%q) path = %s, want %s", name, path, paths[origin])
+				}
+				if got := cm.GetServer(name).Command; got != origin {
+					t.Errorf("server %s uses the %s config, want %s", name, got, origin)
+				}
+			}
+			if !reflect.DeepEqual(origins, tt.wantOrigins) {
+				t.Errorf("origins = %v, want %v", origins, tt.wantOrigins)
+			}
+
+			wantTarget := filepath.Join(root, filepath.FromSlash(tt.wantTarget))
+			if cm.ConfigPath() != wantTarget {
+				t.Errorf("ConfigPath() = %s, want %s", cm.ConfigPath(), wantTarget)
+			}
+			if err := cm.AddServer("new", &ServerConfig{Transport: "stdio", Command: "x"}); err != nil {
+				t.Fatal(err)
+			}
+			if loadServers(t, wantTarget)["new"] == nil {
+				t.Errorf("new server was not written to %s", tt.wantTarget)
+			}
+		})
+	}
+}
+
+func TestTargetLayerKeepsOrigin(t *testing.T) {
+	root := isolateConfig(t)
+	userPath := filepath.Join(root, "xdg", "mcp-cli", "config.json")
+	projectPath := filepath.Join(root, "work", ".mcp-cli", "config.json")
+	writeFile(t, userPath, `{"version": "1.0.0", "servers": {"u": {"transport": "stdio", "command": "x"}}}`)
+	writeFile(t, projectPath, `{"version": "1.0.0", "servers": {"p": {"transport": "stdio", "command": "x"}}}`)
+
+	cm, err := NewConfigManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 修改、重命名和复制都写回服务器所在的配置层
+	if err := cm.UpdateServer("u", func(s *ServerConfig) error { return SetField(s, "command", []string{"y"}) }); err != nil {
+		t.Fatal(err)
+	}
+	if err := cm.CopyServer("u", "u2"); err != nil {
+		t.Fatal(err)
+	}
+
+	user := loadServers(t, userPath)
+	if user["u"] == nil || user["u"].Command != "y" || user["u2"] == nil {
+		t.Errorf("user config = %+v, want updated u and copied u2", user)
+	}
+	if project := loadServers(t, projectPath); len(project) != 1 || project["p"] == nil {
+		t.Errorf("project config = %+v, want only p", project)
+	}
+
+	removed, err := cm.RemoveServer("u")
+	if err != nil || !removed {
+		t.Fatalf("RemoveServer() = %v, %v", removed, err)
+	}
+	if loadServers(t, userPath)["u"] != nil {
+		t.Error("RemoveServer() did not remove the server from the user config")
+	}
+}
@@ -0,0 +1,219 @@
+package config
+
+import (
+	"encoding/json"
+	"maps"
+	"sort"
+)
+
+// Copyright 2025 MCP CLI Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// FieldChange 服务器配置中一个字段的变化，Old 或 New 为空表示字段被新增或删除
+type FieldChange struct {
+	Field string `json:"field"`         // 字段路径，如 command、env.API_KEY
+	Old   string `json:"old,omitempty"` // JSON 表示的旧值
+	New   string `json:"new,omitempty"` // JSON 表示的新值
+}
+
+// DiffServer 比较两个服务器配置，按字段路径排序返回变化。
+// 对象字段（env、headers、auth）按键逐项比较，name 不参与比较。
+func DiffServer(before, after *ServerConfig) []FieldChange {
+	oldFields := flattenServer(before)
+	newFields := flattenServer(after)
+
+	keys := make(map[string]bool, len(oldFields)+len(newFields))
+	for key := range oldFields {
+		keys[key] = true
+	}
+	for key := range newFields {
+		keys[key] = true
+	}
+
+	var changes []FieldChange
+	for key := range keys {
+		if oldFields[key] != newFields[key] {
+			changes = append(changes, FieldChange{Field: key, Old: oldFields[key], New: newFields[key]})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes
+}
+
+// flattenServer 将服务器配置展开为 字段路径 -> JSON 值
+func flattenServer(server *ServerConfig) map[string]string {
+	fields := make(map[string]string)
+	if server == nil {
+		return fields
+	}
+
+	data, _ := json.Marshal(server)
+	var generic map[string]any
+	json.Unmarshal(data, &generic)
+
+	var flatten func(prefix string, value any)
+	flatten = func(prefix string, value any) {
+		if object, ok := value.(map[string]any); ok {
+			for key, item := range object {
+				path := key
+				if prefix != "" {
+					path = prefix + "." + key
+				}
+				flatten(path, item)
+			}
+			return
+		}
+		encoded, _ := json.Marshal(value)
+		fields[prefix] = string(encoded)
+	}
+	delete(generic, "name")
+	flatten("", generic)
+	return fields
+}
+
+// MergeServer 合并服务器配置：incoming 中非空的字段覆盖 existing，env 和 headers 按键合并。
+// 传输方式不同时命令、URL 等连接字段整体使用 incoming 的值，只保留 auth、tls、maxRetries 和超时设置。
+func MergeServer(existing, incoming *ServerConfig) *ServerConfig {
+	if existing.Transport != incoming.Transport {
+		merged := *incoming
+		if merged.Auth == nil {
+			merged.Auth = existing.Auth
+		}
+		if merged.TLS == nil {
+			merged.TLS = existing.TLS
+		}
+		if merged.MaxRetries == 0 {
+			merged.MaxRetries = existing.MaxRetries
+		}
+		if merged.ConnectTimeout == "" {
+			merged.ConnectTimeout = existing.ConnectTimeout
+		}
+		if merged.CallTimeout == "" {
+			merged.CallTimeout = existing.CallTimeout
+		}
+		return &merged
+	}
+
+	merged := *existing
+	if incoming.Command != "" {
+		merged.Command = incoming.Command
+	}
+	if incoming.Args != nil {
+		merged.Args = incoming.Args
+	}
+	if incoming.URL != "" {
+		merged.URL = incoming.URL
+	}
+	if incoming.MaxRetries != 0 {
+		merged.MaxRetries = incoming.MaxRetries
+	}
+	if incoming.Auth != nil {
+		merged.Auth = incoming.Auth
+	}
+	if incoming.TLS != nil {
+		merged.TLS = incoming.TLS
+	}
+	if incoming.ConnectTimeout != "" {
+		merged.ConnectTimeout = incoming.ConnectTimeout
+	}
+	if incoming.CallTimeout != "" {
+		merged.CallTimeout = incoming.CallTimeout
+	}
+	if incoming.Cwd != "" {
+		merged.Cwd = incoming.Cwd
+	}
+	if incoming.InheritEnv != nil {
+		merged.InheritEnv = incoming.InheritEnv
+	}
+	if incoming.ShutdownGrace != "" {
+		merged.ShutdownGrace = incoming.ShutdownGrace
+	}
+	merged.Env = mergeMap(existing.Env, incoming.Env)
+	merged.Headers = mergeMap(existing.Headers, incoming.Headers)
+	return &merged
+}
+
+func mergeMap(existing, incoming map[string]string) map[string]string {
+	if len(incoming) == 0 {
+		return existing
+	}
+	merged := maps.Clone(existing)
+	if merged == nil {
+		merged = make(map[string]string, len(incoming))
+	}
+	maps.Copy(merged, incoming)
+	return merged
+}
+
+// RebaseServer 将 base 到 edited 的修改应用到 latest 上并返回结果，latest 中 base 之后的其他修改保留。
+// 用于在文件锁内重放用户基于旧快照所做的编辑；对象字段（env、headers、auth、tls）按键合并。
+func RebaseServer(base, edited, latest *ServerConfig) (*ServerConfig, error) {
+	merged := rebaseObject(toObject(base), toObject(edited), toObject(latest))
+	data, err := json.Marshal(merged)
+	if err != nil {
+		return nil, err
+	}
+	var server ServerConfig
+	if err := json.Unmarshal(data, &server); err != nil {
+		return nil, err
+	}
+	return &server, nil
+}
+
+// rebaseObject 三方合并 JSON 对象：edited 相对 base 改变的键取 edited 的值，其余保留 latest 的值
+func rebaseObject(base, edited, latest map[string]any) map[string]any {
+	result := maps.Clone(latest)
+	if result == nil {
+		result = make(map[string]any)
+	}
+	keys := make(map[string]bool)
+	for key := range base {
+		keys[key] = true
+	}
+	for key := range edited {
+		keys[key] = true
+	}
+
+	for key := range keys {
+		baseValue, editedValue := base[key], edited[key]
+		if jsonEqual(baseValue, editedValue) {
+			continue
+		}
+		baseObject, baseOK := baseValue.(map[string]any)
+		editedObject, editedOK := editedValue.(map[string]any)
+		latestObject, latestOK := latest[key].(map[string]any)
+		switch {
+		case baseOK && editedOK && latestOK:
+			result[key] = rebaseObject(baseObject, editedObject, latestObject)
+		case editedValue == nil:
+			delete(result, key)
+		default:
+			result[key] = editedValue
+		}
+	}
+	return result
+}
+
+func toObject(server *ServerConfig) map[string]any {
+	data, _ := json.Marshal(server)
+	var object map[string]any
+	json.Unmarshal(data, &object)
+	return object
+}
+
+func jsonEqual(a, b any) bool {
+	x, _ := json.Marshal(a)
+	y, _ := json.Marshal(b)
+	return string(x) == string(y)
+}
@@ -0,0 +1,175 @@
+package config
+
+import (
+	"maps"
+	"reflect"
+	"testing"
+)
+
+// Copyright 2025 MCP CLI Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+func TestDiffServer(t *testing.T) {
+	tests := []struct {
+		name   string
+		before *ServerConfig
+		after  *ServerConfig
+		want   []FieldChange
+	}{
+		{
+			name:   "identical",
+			before: &ServerConfig{Name: "a", Transport: "stdio", Command: "x"},
+			after:  &ServerConfig{Name: "b", Transport: "stdio", Command: "x"},
+		},
+		{
+			name:   "new server",
+			before: nil,
+			after:  &ServerConfig{Transport: "stdio", Command: "x", Args: []string{"-v"}},
+			want: []FieldChange{
+				{Field: "args", New: `["-v"]`},
+				{Field: "command", New: `"x"`},
+				{Field: "transport", New: `"stdio"`},
+			},
+		},
+		{
+			name:   "scalar and list changes",
+			before: &ServerConfig{Transport: "stdio", Command: "x", Args: []string{"a"}, MaxRetries: 2},
+			after:  &ServerConfig{Transport: "stdio", Command: "y", Args: []string{"a", "b"}},
+			want: []FieldChange{
+				{Field: "args", Old: `["a"]`, New: `["a","b"]`},
+				{Field: "command", Old: `"x"`, New: `"y"`},
+				{Field: "maxRetries", Old: "2"},
+			},
+		},
+		{
+			name:   "maps compared per key",
+			before: &ServerConfig{Transport: "stdio", Command: "x", Env: map[string]string{"A": "1", "B": "2"}},
+			after:  &ServerConfig{Transport: "stdio", Command: "x", Env: map[string]string{"A": "1", "B": "3", "C": "4"}},
+			want: []FieldChange{
+				{Field: "env.B", Old: `"2"`, New: `"3"`},
+				{Field: "env.C", New: `"4"`},
+			},
+		},
+		{
+			name:   "nested objects",
+			before: &ServerConfig{Transport: "http", URL: "https://x", Auth: &AuthConfig{ClientID: "a"}},
+			after:  &ServerConfig{Transport: "http", URL: "https://x", TLS: &TLSConfig{InsecureSkipVerify: true}},
+			want: []FieldChange{
+				{Field: "auth.clientId", Old: `"a"`},
+				{Field: "tls.insecureSkipVerify", New: "true"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DiffServer(tt.before, tt.after); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DiffServer() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeServer(t *testing.T) {
+	disabled := false
+	auth := &AuthConfig{ClientID: "cid"}
+	tls := &TLSConfig{CAFile: "ca.pem"}
+
+	tests := []struct {
+		name     string
+		existing *ServerConfig
+		incoming *ServerConfig
+		want     *ServerConfig
+	}{
+		{
+			name: "same transport keeps unset fields and merges maps",
+			existing: &ServerConfig{
+				Name: "s", Transport: "stdio", Command: "old", Args: []string{"a"},
+				Env: map[string]string{"A": "1", "B": "2"}, Cwd: "/w", CallTimeout: "5s", Auth: auth,
+			},
+			incoming: &ServerConfig{
+				Name: "s", Transport: "stdio", Command: "new",
+				Env: map[string]string{"B": "3", "C": "4"}, InheritEnv: &disabled,
+			},
+			want: &ServerConfig{
+				Name: "s", Transport: "stdio", Command: "new", Args: []string{"a"},
+				Env: map[string]string{"A": "1", "B": "3", "C": "4"}, Cwd: "/w", CallTimeout: "5s",
+				Auth: auth, InheritEnv: &disabled,
+			},
+		},
+		{
+			name:     "empty args replace existing args",
+			existing: &ServerConfig{Transport: "stdio", Command: "x", Args: []string{"a"}},
+			incoming: &ServerConfig{Transport: "stdio", Args: []string{}},
+			want:     &ServerConfig{Transport: "stdio", Command: "x", Args: []string{}},
+		},
+		{
+			name: "transport change drops connection fields",
+			existing: &ServerConfig{
+				Transport: "stdio", Command: "x", Env: map[string]string{"A": "1"}, Cwd: "/w",
+				MaxRetries: 3, ConnectTimeout: "10s", Auth: auth, TLS: tls,
+			},
+			incoming: &ServerConfig{Transport: "http", URL: "https://x", Headers: map[string]string{"H": "v"}},
+			want: &ServerConfig{
+				Transport: "http", URL: "https://x", Headers: map[string]string{"H": "v"},
+				MaxRetries: 3, ConnectTimeout: "10s", Auth: auth, TLS: tls,
+			},
+		},
+		{
+			name:     "transport change prefers incoming auth",
+			existing: &ServerConfig{Transport: "sse", URL: "https://x/sse", Auth: auth},
+			incoming: &ServerConfig{Transport: "http", URL: "https://x/mcp", Auth: &AuthConfig{ClientID: "other"}},
+			want:     &ServerConfig{Transport: "http", URL: "https://x/mcp", Auth: &AuthConfig{ClientID: "other"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			existingEnv := maps.Clone(tt.existing.Env)
+			got := MergeServer(tt.existing, tt.incoming)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MergeServer() = %+v, want %+v", got, tt.want)
+			}
+			if !reflect.DeepEqual(tt.existing.Env, existingEnv) {
+				t.Errorf("MergeServer() modified existing env: %v", tt.existing.Env)
+			}
+		})
+	}
+}
+
+func TestRebaseServer(t *testing.T) {
+	base := &ServerConfig{Name: "s", Transport: "stdio", Command: "x", Env: map[string]string{"A": "1", "B": "2"}, Cwd: "/w"}
+	// 用户的编辑：改 command、删除 env.A、增加 env.C
+	edited := &ServerConfig{Name: "s", Transport: "stdio", Command: "y", Env: map[string]string{"B": "2", "C": "3"}, Cwd: "/w"}
+	// 编辑期间其他进程的修改：改 cwd、增加 env.D、设置 auth
+	latest := &ServerConfig{Name: "s", Transport: "stdio", Command: "x", Env: map[string]string{"A": "1", "B": "2", "D": "4"}, Cwd: "/other",
+		Auth: &AuthConfig{ClientID: "cid"}}
+
+	got, err := RebaseServer(base, edited, latest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &ServerConfig{Name: "s", Transport: "stdio", Command: "y", Env: map[string]string{"B": "2", "C": "3", "D": "4"}, Cwd: "/other",
+		Auth: &AuthConfig{ClientID: "cid"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("RebaseServer() = %+v, want %+v", got, want)
+	}
+
+	// 删除整个对象字段
+	edited = base.Clone()
+	edited.Env = nil
+	if got, _ := RebaseServer(base, edited, latest); got.Env != nil {
+		t.Errorf("RebaseServer() env = %v, want removed", got.Env)
+	}
+}
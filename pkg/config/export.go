@@ -0,0 +1,352 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Copyright 2025 MCP CLI Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// 支持导出的宿主配置格式
+const (
+	FormatGoMCPCLI      = "go-mcp-cli"
+	FormatClaudeDesktop = "claude-desktop"
+	FormatVSCode        = "vscode"
+	FormatCursor        = "cursor"
+	FormatZed           = "zed"
+	FormatContinue      = "continue"
+)
+
+// ExportFormats 所有导出格式
+var ExportFormats = []string{FormatClaudeDesktop, FormatVSCode, FormatCursor, FormatZed, FormatContinue, FormatGoMCPCLI}
+
+// VSCodeConfig VS Code mcp.json 格式
+type VSCodeConfig struct {
+	Servers map[string]*VSCodeServerConfig `json:"servers"`
+}
+
+// VSCodeServerConfig VS Code 服务器配置，type 为 stdio、sse 或 http
+type VSCodeServerConfig struct {
+	Type    string            `json:"type"`
+	Command string            `json:"command,omitempty"`
+	Args    []string          `json:"args,omitempty"`
+	Env     map[string]string `json:"env,omitempty"`
+	URL     string            `json:"url,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// ZedConfig Zed settings.json 中的 context_servers 片段
+type ZedConfig struct {
+	ContextServers map[string]*ZedServerConfig `json:"context_servers"`
+}
+
+// ZedServerConfig Zed 自定义上下文服务器配置
+type ZedServerConfig struct {
+	Source  string            `json:"source"`
+	Command string            `json:"command,omitempty"`
+	Args    []string          `json:"args,omitempty"`
+	Env     map[string]string `json:"env,omitempty"`
+	URL     string            `json:"url,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// ContinueConfig Continue config.yaml（或 .continue/mcpServers 下的配置块）格式
+type ContinueConfig struct {
+	Name       string                  `json:"name"`
+	Version    string                  `json:"version"`
+	Schema     string                  `json:"schema"`
+	MCPServers []*ContinueServerConfig `json:"mcpServers"`
+}
+
+// ContinueServerConfig Continue 服务器配置，type 为 stdio、sse 或 streamable-http
+type ContinueServerConfig struct {
+	Name           string                  `json:"name"`
+	Type           string                  `json:"type"`
+	Command        string                  `json:"command,omitempty"`
+	Args           []string                `json:"args,omitempty"`
+	Env            map[string]string       `json:"env,omitempty"`
+	URL            string                  `json:"url,omitempty"`
+	RequestOptions *ContinueRequestOptions `json:"requestOptions,omitempty"`
+}
+
+// ContinueRequestOptions Continue 远程服务器的请求选项
+type ContinueRequestOptions struct {
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// Export 将服务器配置转换为指定宿主的配置格式，返回值用于 JSON/YAML 序列化。
+// 环境变量引用按宿主的写法转换（见 translateReferences），auth、cwd 等宿主不支持的字段不会导出，
+// 这些信息丢失以及宿主无法展开的引用作为警告返回。
+func Export(servers map[string]*ServerConfig, format string) (any, []string, error) {
+	names := make([]string, 0, len(servers))
+	for name := range servers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if format == FormatGoMCPCLI {
+		return &Config{Version: "1.0.0", Servers: servers}, nil, nil
+	}
+	if !slices.Contains(ExportFormats, format) {
+		return nil, nil, fmt.Errorf("unknown export format: %s (valid: %s)", format, strings.Join(ExportFormats, ", "))
+	}
+
+	// 宿主配置需要明确的传输方式，未填写 transport 时按连接时的规则推断
+	var warnings []string
+	normalized := make(map[string]*ServerConfig, len(servers))
+	for _, name := range names {
+		copied := *servers[name]
+		copied.Transport = exportTransport(&copied)
+		if dropped := droppedFields(&copied); len(dropped) > 0 {
+			warnings = append(warnings, fmt.Sprintf("%s: %s not supported by %s, not exported", name, strings.Join(dropped, ", "), format))
+		}
+		warnings = append(warnings, translateServer(name, &copied, format)...)
+		normalized[name] = &copied
+	}
+	servers = normalized
+
+	switch format {
+	case FormatClaudeDesktop, FormatCursor:
+		exported := &ClaudeDesktopConfig{MCPServers: make(map[string]*ClaudeServerConfig, len(servers))}
+		for name, server := range servers {
+			entry := &ClaudeServerConfig{
+				Command: server.Command,
+				Args:    server.Args,
+				Env:     server.Env,
+			}
+			if server.Transport != "stdio" {
+				entry = &ClaudeServerConfig{URL: server.URL, Headers: server.Headers}
+				// Cursor 根据 URL 自动识别传输方式，Claude 需要显式指定 type
+				if format == FormatClaudeDesktop {
+					entry.Type = server.Transport
+				}
+			}
+			exported.MCPServers[name] = entry
+		}
+		return exported, warnings, nil
+
+	case FormatVSCode:
+		exported := &VSCodeConfig{Servers: make(map[string]*VSCodeServerConfig, len(servers))}
+		for name, server := range servers {
+			entry := &VSCodeServerConfig{Type: server.Transport}
+			if server.Transport == "stdio" {
+				entry.Command, entry.Args, entry.Env = server.Command, server.Args, server.Env
+			} else {
+				entry.URL, entry.Headers = server.URL, server.Headers
+			}
+			exported.Servers[name] = entry
+		}
+		return exported, warnings, nil
+
+	case FormatZed:
+		exported := &ZedConfig{ContextServers: make(map[string]*ZedServerConfig, len(servers))}
+		for name, server := range servers {
+			entry := &ZedServerConfig{Source: "custom"}
+			if server.Transport == "stdio" {
+				entry.Command, entry.Args, entry.Env = server.Command, server.Args, server.Env
+			} else {
+				entry.URL, entry.Headers = server.URL, server.Headers
+			}
+			exported.ContextServers[name] = entry
+		}
+		return exported, warnings, nil
+
+	case FormatContinue:
+		exported := &ContinueConfig{
+			Name:       "mcp-cli",
+			Version:    "0.0.1",
+			Schema:     "v1",
+			MCPServers: make([]*ContinueServerConfig, 0, len(servers)),
+		}
+		for _, name := range names {
+			server := servers[name]
+			entry := &ContinueServerConfig{Name: name}
+			switch server.Transport {
+			case "stdio":
+				entry.Type = "stdio"
+				entry.Command, entry.Args, entry.Env = server.Command, server.Args, server.Env
+			case "sse":
+				entry.Type = "sse"
+				entry.URL = server.URL
+			default:
+				entry.Type = "streamable-http"
+				entry.URL = server.URL
+			}
+			if server.Transport != "stdio" && len(server.Headers) > 0 {
+				entry.RequestOptions = &ContinueRequestOptions{Headers: server.Headers}
+			}
+			exported.MCPServers = append(exported.MCPServers, entry)
+		}
+		return exported, warnings, nil
+
+	default:
+		return nil, nil, fmt.Errorf("unknown export format: %s (valid: %s)", format, strings.Join(ExportFormats, ", "))
+	}
+}
+
+// droppedFields 返回服务器中已设置、但宿主配置无法表示的字段
+func droppedFields(server *ServerConfig) []string {
+	var dropped []string
+	check := func(field string, set bool) {
+		if set {
+			dropped = append(dropped, field)
+		}
+	}
+	if server.Transport == "stdio" {
+		check("cwd", server.Cwd != "")
+		check("inheritEnv", server.InheritEnv != nil)
+		check("shutdownGrace", server.ShutdownGrace != "")
+	}
+	check("maxRetries", server.MaxRetries != 0)
+	check("connectTimeout", server.ConnectTimeout != "")
+	check("callTimeout", server.CallTimeout != "")
+	check("auth", server.Auth != nil)
+	check("tls", server.TLS != nil)
+	return dropped
+}
+
+// translateServer 转换服务器中会导出的字段里的引用，返回转换中丢失信息的警告
+func translateServer(name string, server *ServerConfig, format string) []string {
+	var warnings []string
+	translate := func(field, value string) string {
+		translated, warning := translateReferences(value, format)
+		if warning != "" {
+			warnings = append(warnings, fmt.Sprintf("%s.%s: %s", name, field, warning))
+		}
+		return translated
+	}
+	translateMap := func(prefix string, values map[string]string) map[string]string {
+		if values == nil {
+			return nil
+		}
+		keys := make([]string, 0, len(values))
+		for key := range values {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		result := make(map[string]string, len(values))
+		for _, key := range keys {
+			result[key] = translate(prefix+"."+key, values[key])
+		}
+		return result
+	}
+
+	if server.Transport == "stdio" {
+		server.Command = translate("command", server.Command)
+		if server.Args != nil {
+			args := make([]string, len(server.Args))
+			for i, arg := range server.Args {
+				args[i] = translate(fmt.Sprintf("args[%d]", i), arg)
+			}
+			server.Args = args
+		}
+		server.Env = translateMap("env", server.Env)
+	} else {
+		server.URL = translate("url", server.URL)
+		server.Headers = translateMap("headers", server.Headers)
+	}
+	return warnings
+}
+
+// translateReferences 将值中的环境变量引用（${VAR}、${env:VAR}、${VAR:-default}）转换为宿主的写法：
+// VS Code 和 Cursor 使用 ${env:VAR}，不支持默认值；其他宿主不展开引用，原样保留。
+// ${secret:...} 和 ${file:...} 由 LocalReferences 单独检查，这里不处理。
+// 转换丢失信息或宿主无法展开时返回警告。
+func translateReferences(value, format string) (string, string) {
+	if !strings.Contains(value, "${") {
+		return value, ""
+	}
+
+	var kept, defaults []string
+	translated := interpolationPattern.ReplaceAllStringFunc(value, func(ref string) string {
+		expr := ref[2 : len(ref)-1]
+		if strings.HasPrefix(expr, "secret:") || strings.HasPrefix(expr, "file:") {
+			return ref
+		}
+		name, _, hasDefault := strings.Cut(strings.TrimPrefix(expr, "env:"), ":-")
+		if !varNamePattern.MatchString(name) {
+			return ref
+		}
+
+		switch format {
+		case FormatVSCode, FormatCursor:
+			if hasDefault {
+				defaults = append(defaults, ref)
+			}
+			return "${env:" + name + "}"
+		default:
+			kept = append(kept, ref)
+			return ref
+		}
+	})
+
+	switch {
+	case len(defaults) > 0:
+		return translated, fmt.Sprintf("%s does not support default values, exported %s without the default", format, strings.Join(defaults, ", "))
+	case len(kept) > 0:
+		return translated, fmt.Sprintf("%s does not expand environment variables, %s exported verbatim", format, strings.Join(kept, ", "))
+	}
+	return translated, ""
+}
+
+// exportTransport 返回服务器的传输方式，未填写时根据 command 和 url 推断
+func exportTransport(server *ServerConfig) string {
+	switch {
+	case server.Transport != "":
+		return server.Transport
+	case server.Command != "":
+		return "stdio"
+	case server.URL != "":
+		return detectTransportType(server.URL)
+	default:
+		return "stdio"
+	}
+}
+
+// LocalReferences 返回导出到其他宿主后会包含 ${secret:...} 或 ${file:...} 引用的字段，
+// 如 github.env.GITHUB_TOKEN。这些引用只有 mcp-cli 能解析，go-mcp-cli 格式不检查。
+func LocalReferences(servers map[string]*ServerConfig, format string) []string {
+	if format == FormatGoMCPCLI {
+		return nil
+	}
+
+	var refs []string
+	check := func(field, value string) {
+		if strings.Contains(value, "${secret:") || strings.Contains(value, "${file:") {
+			refs = append(refs, field)
+		}
+	}
+	checkMap := func(prefix string, values map[string]string) {
+		for key, value := range values {
+			check(prefix+"."+key, value)
+		}
+	}
+	for name, server := range servers {
+		if exportTransport(server) == "stdio" {
+			check(name+".command", server.Command)
+			for i, arg := range server.Args {
+				check(fmt.Sprintf("%s.args[%d]", name, i), arg)
+			}
+			checkMap(name+".env", server.Env)
+		} else {
+			check(name+".url", server.URL)
+			checkMap(name+".headers", server.Headers)
+		}
+	}
+	sort.Strings(refs)
+	return refs
+}
@@ -0,0 +1,177 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Copyright 2025 MCP CLI Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+func TestExport(t *testing.T) {
+	disabled := false
+	servers := map[string]*ServerConfig{
+		"local": {
+			Name: "local", Transport: "stdio", Command: "node", Args: []string{"${HOME}/srv.js"},
+			Env: map[string]string{"TOKEN": "${API_TOKEN}"}, Cwd: "/w", InheritEnv: &disabled,
+		},
+		"remote": {
+			Name: "remote", Transport: "http", URL: "https://x/mcp",
+			Headers: map[string]string{"Authorization": "Bearer ${env:TOK}"}, MaxRetries: 3,
+		},
+		"legacy": {Name: "legacy", URL: "https://x/sse"},
+	}
+
+	tests := []struct {
+		format       string
+		want         string
+		wantWarnings []string
+	}{
+		{
+			format: FormatClaudeDesktop,
+			want: `{"mcpServers":{` +
+				`"legacy":{"type":"sse","url":"https://x/sse"},` +
+				`"local":{"command":"node","args":["${HOME}/srv.js"],"env":{"TOKEN":"${API_TOKEN}"}},` +
+				`"remote":{"type":"http","url":"https://x/mcp","headers":{"Authorization":"Bearer ${env:TOK}"}}}}`,
+			wantWarnings: []string{
+				"local: cwd, inheritEnv not supported by claude-desktop, not exported",
+				"local.args[0]: claude-desktop does not expand environment variables, ${HOME} exported verbatim",
+				"local.env.TOKEN: claude-desktop does not expand environment variables, ${API_TOKEN} exported verbatim",
+				"remote: maxRetries not supported by claude-desktop, not exported",
+				"remote.headers.Authorization: claude-desktop does not expand environment variables, ${env:TOK} exported verbatim",
+			},
+		},
+		{
+			format: FormatCursor,
+			want: `{"mcpServers":{` +
+				`"legacy":{"url":"https://x/sse"},` +
+				`"local":{"command":"node","args":["${env:HOME}/srv.js"],"env":{"TOKEN":"${env:API_TOKEN}"}},` +
+				`"remote":{"url":"https://x/mcp","headers":{"Authorization":"Bearer ${env:TOK}"}}}}`,
+			wantWarnings: []string{
+				"local: cwd, inheritEnv not supported by cursor, not exported",
+				"remote: maxRetries not supported by cursor, not exported",
+			},
+		},
+		{
+			format: FormatVSCode,
+			want: `{"servers":{` +
+				`"legacy":{"type":"sse","url":"https://x/sse"},` +
+				`"local":{"type":"stdio","command":"node","args":["${env:HOME}/srv.js"],"env":{"TOKEN":"${env:API_TOKEN}"}},` +
+				`"remote":{"type":"http","url":"https://x/mcp","headers":{"Authorization":"Bearer ${env:TOK}"}}}}`,
+			wantWarnings: []string{
+				"local: cwd, inheritEnv not supported by vscode, not exported",
+				"remote: maxRetries not supported by vscode, not exported",
+			},
+		},
+		{
+			format: FormatZed,
+			want: `{"context_servers":{` +
+				`"legacy":{"source":"custom","url":"https://x/sse"},` +
+				`"local":{"source":"custom","command":"node","args":["${HOME}/srv.js"],"env":{"TOKEN":"${API_TOKEN}"}},` +
+				`"remote":{"source":"custom","url":"https://x/mcp","headers":{"Authorization":"Bearer ${env:TOK}"}}}}`,
+			wantWarnings: []string{
+				"local: cwd, inheritEnv not supported by zed, not exported",
+				"local.args[0]: zed does not expand environment variables, ${HOME} exported verbatim",
+				"local.env.TOKEN: zed does not expand environment variables, ${API_TOKEN} exported verbatim",
+				"remote: maxRetries not supported by zed, not exported",
+				"remote.headers.Authorization: zed does not expand environment variables, ${env:TOK} exported verbatim",
+			},
+		},
+		{
+			format: FormatContinue,
+			want: `{"name":"mcp-cli","version":"0.0.1","schema":"v1","mcpServers":[` +
+				`{"name":"legacy","type":"sse","url":"https://x/sse"},` +
+				`{"name":"local","type":"stdio","command":"node","args":["${HOME}/srv.js"],"env":{"TOKEN":"${API_TOKEN}"}},` +
+				`{"name":"remote","type":"streamable-http","url":"https://x/mcp","requestOptions":{"headers":{"Authorization":"Bearer ${env:TOK}"}}}]}`,
+			wantWarnings: []string{
+				"local: cwd, inheritEnv not supported by continue, not exported",
+				"local.args[0]: continue does not expand environment variables, ${HOME} exported verbatim",
+				"local.env.TOKEN: continue does not expand environment variables, ${API_TOKEN} exported verbatim",
+				"remote: maxRetries not supported by continue, not exported",
+				"remote.headers.Authorization: continue does not expand environment variables, ${env:TOK} exported verbatim",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			exported, warnings, err := Export(servers, tt.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, err := json.Marshal(exported)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("Export() =\n%s\nwant\n%s", data, tt.want)
+			}
+			if !reflect.DeepEqual(warnings, tt.wantWarnings) {
+				t.Errorf("Export() warnings =\n%s\nwant\n%s", strings.Join(warnings, "\n"), strings.Join(tt.wantWarnings, "\n"))
+			}
+		})
+	}
+
+	// 导出不修改原配置
+	if servers["local"].Args[0] != "${HOME}/srv.js" || servers["local"].Env["TOKEN"] != "${API_TOKEN}" || servers["legacy"].Transport != "" {
+		t.Errorf("Export() modified the servers: %+v", servers)
+	}
+	if _, _, err := Export(servers, "emacs"); err == nil {
+		t.Error("Export() with an unknown format succeeded")
+	}
+}
+
+func TestTranslateReferences(t *testing.T) {
+	tests := []struct {
+		value       string
+		format      string
+		want        string
+		wantWarning string
+	}{
+		{value: "plain", format: FormatVSCode, want: "plain"},
+		{value: "${A}-${env:B}", format: FormatVSCode, want: "${env:A}-${env:B}"},
+		{value: "${A:-x}", format: FormatCursor, want: "${env:A}", wantWarning: "default values"},
+		{value: "${secret:t} ${file:~/k}", format: FormatVSCode, want: "${secret:t} ${file:~/k}"},
+		{value: "${not valid}", format: FormatVSCode, want: "${not valid}"},
+		{value: "${A}", format: FormatZed, want: "${A}", wantWarning: "does not expand"},
+	}
+
+	for _, tt := range tests {
+		got, warning := translateReferences(tt.value, tt.format)
+		if got != tt.want {
+			t.Errorf("translateReferences(%q, %s) = %q, want %q", tt.value, tt.format, got, tt.want)
+		}
+		if (tt.wantWarning == "") != (warning == "") || !strings.Contains(warning, tt.wantWarning) {
+			t.Errorf("translateReferences(%q, %s) warning = %q, want %q", tt.value, tt.format, warning, tt.wantWarning)
+		}
+	}
+}
+
+func TestLocalReferences(t *testing.T) {
+	servers := map[string]*ServerConfig{
+		"a": {Transport: "stdio", Command: "x", Args: []string{"${file:~/key}"}, Env: map[string]string{"T": "${secret:t}", "P": "${PATH}"}},
+		"b": {URL: "https://x/mcp", Headers: map[string]string{"Authorization": "Bearer ${secret:b}"}},
+		"c": {Transport: "stdio", Command: "x", URL: "${secret:ignored}"},
+	}
+
+	want := []string{"a.args[0]", "a.env.T", "b.headers.Authorization"}
+	if got := LocalReferences(servers, FormatVSCode); !reflect.DeepEqual(got, want) {
+		t.Errorf("LocalReferences() = %v, want %v", got, want)
+	}
+	if got := LocalReferences(servers, FormatGoMCPCLI); got != nil {
+		t.Errorf("LocalReferences() for go-mcp-cli = %v, want nil", got)
+	}
+}
@@ -0,0 +1,219 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Copyright 2025 MCP CLI Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Clone 返回服务器配置的深拷贝
+func (s *ServerConfig) Clone() *ServerConfig {
+	data, _ := json.Marshal(s)
+	var clone ServerConfig
+	json.Unmarshal(data, &clone)
+	return &clone
+}
+
+// SetField 按字段路径设置服务器配置，路径与 JSON 字段名一致，如 url、args、env.API_KEY、auth.clientId。
+//
+// 列表字段使用全部 values（也可以传一个 JSON 数组）；env、headers 整体设置时每个值写成 KEY=VALUE；
+// 其他字段只接受一个值。
+func SetField(server *ServerConfig, path string, values []string) error {
+	return updateField(server, path, values, false)
+}
+
+// UnsetField 清除字段，或删除 env、headers 中的一个键
+func UnsetField(server *ServerConfig, path string) error {
+	return updateField(server, path, nil, true)
+}
+
+func updateField(server *ServerConfig, path string, values []string, unset bool) error {
+	if path == "name" {
+		return fmt.Errorf("use 'mcp-cli rename' to change a server's name")
+	}
+
+	segments := strings.Split(path, ".")
+	v := reflect.ValueOf(server).Elem()
+	schema := schemaOf(v.Type())
+	for i, segment := range segments {
+		switch v.Kind() {
+		case reflect.Struct:
+			field, ok := fieldByTag(v.Type(), segment)
+			if !ok {
+				return fmt.Errorf("unknown field %q%s", strings.Join(segments[:i+1], "."), suggestField(segment, schema.fields))
+			}
+			fv := v.FieldByIndex(field.Index)
+			if i == len(segments)-1 {
+				return assignField(fv, path, values, unset)
+			}
+			schema = schema.fields[segment]
+			if fv.Kind() == reflect.Pointer {
+				if fv.IsNil() {
+					if unset {
+						return nil
+					}
+					fv.Set(reflect.New(fv.Type().Elem()))
+				}
+				fv = fv.Elem()
+			}
+			v = fv
+
+		case reflect.Map:
+			// 剩余部分整体作为键，请求头名称中可能包含点
+			key := reflect.ValueOf(strings.Join(segments[i:], "."))
+			if unset {
+				if !v.IsNil() {
+					v.SetMapIndex(key, reflect.Value{})
+				}
+				return nil
+			}
+			if len(values) != 1 {
+				return fmt.Errorf("%s takes exactly one value", path)
+			}
+			if v.IsNil() {
+				v.Set(reflect.MakeMap(v.Type()))
+			}
+			v.SetMapIndex(key, reflect.ValueOf(values[0]))
+			return nil
+
+		default:
+			return fmt.Errorf("field %q has no sub-fields", strings.Join(segments[:i], "."))
+		}
+	}
+	return nil
+}
+
+// fieldByTag 按 json 标签查找结构体字段
+func fieldByTag(t reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if tag == name && field.IsExported() {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// assignField 将命令行参数转换为字段类型后赋值
+func assignField(fv reflect.Value, path string, values []string, unset bool) error {
+	if unset {
+		fv.Set(reflect.Zero(fv.Type()))
+		return nil
+	}
+
+	single := func() (string, error) {
+		if len(values) != 1 {
+			return "", fmt.Errorf("%s takes exactly one value", path)
+		}
+		return values[0], nil
+	}
+
+	switch {
+	case fv.Kind() == reflect.String:
+		value, err := single()
+		if err != nil {
+			return err
+		}
+		fv.SetString(value)
+
+	case fv.Kind() == reflect.Int:
+		value, err := single()
+		if err != nil {
+			return err
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%s must be an integer", path)
+		}
+		fv.SetInt(int64(n))
+
+	case fv.Kind() == reflect.Bool:
+		value, err := single()
+		if err != nil {
+			return err
+		}
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%s must be true or false", path)
+		}
+		fv.SetBool(b)
+
+	case fv.Type() == reflect.TypeOf([]string(nil)):
+		if len(values) == 1 && strings.HasPrefix(values[0], "[") {
+			return decodeField(fv, path, values[0])
+		}
+		fv.Set(reflect.ValueOf(append([]string{}, values...)))
+
+	case fv.Type() == reflect.TypeOf(map[string]string(nil)):
+		if len(values) == 1 && strings.HasPrefix(values[0], "{") {
+			return decodeField(fv, path, values[0])
+		}
+		m := make(map[string]string, len(values))
+		for _, value := range values {
+			key, val, ok := strings.Cut(value, "=")
+			if !ok {
+				return fmt.Errorf("%s values must be KEY=VALUE, got %q", path, value)
+			}
+			m[key] = val
+		}
+		fv.Set(reflect.ValueOf(m))
+
+	default:
+		// 嵌套对象（如 auth）以 JSON 给出
+		value, err := single()
+		if err != nil {
+			return err
+		}
+		return decodeField(fv, path, value)
+	}
+	return nil
+}
+
+// decodeField 将 JSON 值解码到字段，拒绝未知字段
+func decodeField(fv reflect.Value, path, value string) error {
+	target := reflect.New(fv.Type())
+	dec := json.NewDecoder(bytes.NewReader([]byte(value)))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(target.Interface()); err != nil {
+		return fmt.Errorf("invalid JSON for %s: %w", path, err)
+	}
+	fv.Set(target.Elem())
+	return nil
+}
+
+// ParseServer 解析单个服务器配置的 JSON，未知字段和重复的键会报告所在行
+func ParseServer(data []byte) (*ServerConfig, error) {
+	stripped := stripJSONC(data)
+	var server ServerConfig
+	if err := json.Unmarshal(stripped, &server); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return nil, &LineError{Line: lineAt(stripped, syntaxErr.Offset), Message: err.Error()}
+		}
+		return nil, err
+	}
+	c := &fieldChecker{data: stripped, dec: json.NewDecoder(bytes.NewReader(stripped))}
+	if err := c.value(configSchema.fields["servers"].values, "server"); err != nil {
+		return nil, err
+	}
+	return &server, nil
+}
@@ -0,0 +1,253 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Copyright 2025 MCP CLI Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+func TestSetField(t *testing.T) {
+	disabled := false
+
+	tests := []struct {
+		name    string
+		server  *ServerConfig
+		path    string
+		values  []string
+		want    *ServerConfig
+		wantErr string
+	}{
+		{
+			name:   "string",
+			server: &ServerConfig{Transport: "http", URL: "https://old"},
+			path:   "url",
+			values: []string{"https://new"},
+			want:   &ServerConfig{Transport: "http", URL: "https://new"},
+		},
+		{
+			name:   "integer",
+			server: &ServerConfig{},
+			path:   "maxRetries",
+			values: []string{"3"},
+			want:   &ServerConfig{MaxRetries: 3},
+		},
+		{
+			name:    "integer rejects text",
+			server:  &ServerConfig{},
+			path:    "maxRetries",
+			values:  []string{"three"},
+			wantErr: "maxRetries must be an integer",
+		},
+		{
+			name:   "duration",
+			server: &ServerConfig{},
+			path:   "callTimeout",
+			values: []string{"1m"},
+			want:   &ServerConfig{CallTimeout: "1m"},
+		},
+		{
+			name:   "pointer bool",
+			server: &ServerConfig{},
+			path:   "inheritEnv",
+			values: []string{"false"},
+			want:   &ServerConfig{InheritEnv: &disabled},
+		},
+		{
+			name:   "list from values",
+			server: &ServerConfig{Args: []string{"old"}},
+			path:   "args",
+			values: []string{"-a", "-b"},
+			want:   &ServerConfig{Args: []string{"-a", "-b"}},
+		},
+		{
+			name:   "list from JSON",
+			server: &ServerConfig{},
+			path:   "args",
+			values: []string{`["x y","z"]`},
+			want:   &ServerConfig{Args: []string{"x y", "z"}},
+		},
+		{
+			name:   "whole map from KEY=VALUE",
+			server: &ServerConfig{Env: map[string]string{"OLD": "1"}},
+			path:   "env",
+			values: []string{"A=1", "B=x=y"},
+			want:   &ServerConfig{Env: map[string]string{"A": "1", "B": "x=y"}},
+		},
+		{
+			name:    "whole map rejects bare value",
+			server:  &ServerConfig{},
+			path:    "env",
+			values:  []string{"A"},
+			wantErr: "env values must be KEY=VALUE",
+		},
+		{
+			name:   "single map key",
+			server: &ServerConfig{Env: map[string]string{"A": "1"}},
+			path:   "env.B",
+			values: []string{"2"},
+			want:   &ServerConfig{Env: map[string]string{"A": "1", "B": "2"}},
+		},
+		{
+			name:   "map key containing dots",
+			server: &ServerConfig{},
+			path:   "headers.X-Api.Key",
+			values: []string{"k"},
+			want:   &ServerConfig{Headers: map[string]string{"X-Api.Key": "k"}},
+		},
+		{
+			name:   "nested field creates parent",
+			server: &ServerConfig{},
+			path:   "auth.clientId",
+			values: []string{"cid"},
+			want:   &ServerConfig{Auth: &AuthConfig{ClientID: "cid"}},
+		},
+		{
+			name:   "nested object from JSON",
+			server: &ServerConfig{},
+			path:   "auth",
+			values: []string{`{"clientId":"cid","scopes":["read"]}`},
+			want:   &ServerConfig{Auth: &AuthConfig{ClientID: "cid", Scopes: []string{"read"}}},
+		},
+		{
+			name:    "nested object rejects unknown field",
+			server:  &ServerConfig{},
+			path:    "auth",
+			values:  []string{`{"client":"cid"}`},
+			wantErr: "invalid JSON for auth",
+		},
+		{
+			name:    "too many values",
+			server:  &ServerConfig{},
+			path:    "command",
+			values:  []string{"a", "b"},
+			wantErr: "command takes exactly one value",
+		},
+		{
+			name:    "unknown field",
+			server:  &ServerConfig{},
+			path:    "comand",
+			values:  []string{"x"},
+			wantErr: `unknown field "comand" (did you mean "command"?)`,
+		},
+		{
+			name:    "unknown nested field",
+			server:  &ServerConfig{},
+			path:    "auth.scope",
+			values:  []string{"x"},
+			wantErr: `unknown field "auth.scope" (did you mean "scopes"?)`,
+		},
+		{
+			name:    "scalar has no sub-fields",
+			server:  &ServerConfig{},
+			path:    "command.x",
+			values:  []string{"x"},
+			wantErr: `field "command" has no sub-fields`,
+		},
+		{
+			name:    "name is not settable",
+			server:  &ServerConfig{},
+			path:    "name",
+			values:  []string{"x"},
+			wantErr: "mcp-cli rename",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := SetField(tt.server, tt.path, tt.values)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("SetField(%q) error = %v, want %q", tt.path, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SetField(%q): %v", tt.path, err)
+			}
+			if !reflect.DeepEqual(tt.server, tt.want) {
+				t.Errorf("SetField(%q) = %+v, want %+v", tt.path, tt.server, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnsetField(t *testing.T) {
+	tests := []struct {
+		name   string
+		server *ServerConfig
+		path   string
+		want   *ServerConfig
+	}{
+		{
+			name:   "scalar",
+			server: &ServerConfig{Transport: "http", URL: "https://x"},
+			path:   "url",
+			want:   &ServerConfig{Transport: "http"},
+		},
+		{
+			name:   "whole map",
+			server: &ServerConfig{Env: map[string]string{"A": "1"}},
+			path:   "env",
+			want:   &ServerConfig{},
+		},
+		{
+			name:   "map key",
+			server: &ServerConfig{Env: map[string]string{"A": "1", "B": "2"}},
+			path:   "env.A",
+			want:   &ServerConfig{Env: map[string]string{"B": "2"}},
+		},
+		{
+			name:   "missing map key",
+			server: &ServerConfig{},
+			path:   "headers.X-Token",
+			want:   &ServerConfig{},
+		},
+		{
+			name:   "nested field",
+			server: &ServerConfig{Auth: &AuthConfig{ClientID: "cid", Scopes: []string{"s"}}},
+			path:   "auth.scopes",
+			want:   &ServerConfig{Auth: &AuthConfig{ClientID: "cid"}},
+		},
+		{
+			name:   "nested field under missing parent",
+			server: &ServerConfig{},
+			path:   "auth.clientId",
+			want:   &ServerConfig{},
+		},
+		{
+			name:   "whole object",
+			server: &ServerConfig{Auth: &AuthConfig{ClientID: "cid"}},
+			path:   "auth",
+			want:   &ServerConfig{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := UnsetField(tt.server, tt.path); err != nil {
+				t.Fatalf("UnsetField(%q): %v", tt.path, err)
+			}
+			if !reflect.DeepEqual(tt.server, tt.want) {
+				t.Errorf("UnsetField(%q) = %+v, want %+v", tt.path, tt.server, tt.want)
+			}
+		})
+	}
+
+	if err := UnsetField(&ServerConfig{}, "bogus"); err == nil {
+		t.Error("UnsetField(bogus) succeeded, want unknown field error")
+	}
+}
@@ -0,0 +1,486 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Copyright 2025 MCP CLI Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Imported 从宿主配置文件导入的结果
+type Imported struct {
+	Format   string // 识别出的格式，取值同 ExportFormats
+	Config   *Config
+	Warnings []string // 被忽略的字段和条目
+}
+
+// LoadHostConfig 读取并识别宿主配置文件
+func LoadHostConfig(path string) (*Imported, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseHostConfig(data)
+}
+
+// ParseHostConfig 自动识别并解析以下格式的配置：
+//   - go-mcp-cli：servers 下的条目带 transport
+//   - Claude Desktop / Cursor：mcpServers 对象
+//   - VS Code：mcp.json 中的 servers，或 settings.json 中的 mcp.servers
+//   - Zed：settings.json 中的 context_servers
+//   - Continue：config.yaml 或配置块中的 mcpServers 列表
+//
+// JSON 允许注释和尾随逗号（VS Code 和 Zed 的配置文件都使用 JSONC）。
+func ParseHostConfig(data []byte) (*Imported, error) {
+	stripped := stripJSONC(data)
+	isJSON := true
+	var doc map[string]any
+	if err := json.Unmarshal(stripped, &doc); err != nil {
+		isJSON = false
+		if yamlErr := yaml.Unmarshal(data, &doc); yamlErr != nil || doc == nil {
+			var syntaxErr *json.SyntaxError
+			if errors.As(err, &syntaxErr) {
+				err = &LineError{Line: lineAt(stripped, syntaxErr.Offset), Message: err.Error()}
+			}
+			return nil, fmt.Errorf("failed to parse config: %w", err)
+		}
+	}
+
+	imported := &Imported{Config: newConfig()}
+
+	switch {
+	case isNativeConfig(doc):
+		var config Config
+		if isJSON {
+			// go-mcp-cli 格式严格校验字段，其他宿主的格式以警告方式报告
+			if err := checkFields(stripped); err != nil {
+				return nil, err
+			}
+			if err := json.Unmarshal(stripped, &config); err != nil {
+				return nil, fmt.Errorf("failed to parse config: %w", err)
+			}
+		} else {
+			// YAML 写成的 go-mcp-cli 配置：经 JSON 转换后再解析
+			raw, _ := json.Marshal(doc)
+			if err := json.Unmarshal(raw, &config); err != nil {
+				return nil, fmt.Errorf("failed to parse config: %w", err)
+			}
+		}
+		for name, server := range config.Servers {
+			if server.Name == "" {
+				server.Name = name
+			}
+		}
+		imported.Format = FormatGoMCPCLI
+		imported.Config = &config
+
+	case doc["mcpServers"] != nil:
+		switch servers := doc["mcpServers"].(type) {
+		case map[string]any:
+			imported.Format = FormatClaudeDesktop
+			imported.importEntries(servers, imported.importClaudeServer)
+		case []any:
+			imported.Format = FormatContinue
+			imported.importContinueServers(servers)
+		default:
+			return nil, fmt.Errorf("unsupported mcpServers value")
+		}
+
+	case doc["servers"] != nil || doc["mcp"] != nil:
+		servers, ok := doc["servers"].(map[string]any)
+		if !ok {
+			mcpSection, _ := doc["mcp"].(map[string]any)
+			servers, ok = mcpSection["servers"].(map[string]any)
+		}
+		if !ok {
+			return nil, fmt.Errorf("unsupported servers value")
+		}
+		imported.Format = FormatVSCode
+		if doc["inputs"] != nil {
+			imported.Warnings = append(imported.Warnings, "inputs are not supported, replace ${input:...} references with ${env:...} or ${secret:...}")
+		}
+		imported.importEntries(servers, imported.importVSCodeServer)
+
+	case doc["context_servers"] != nil:
+		servers, ok := doc["context_servers"].(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("unsupported context_servers value")
+		}
+		imported.Format = FormatZed
+		imported.importEntries(servers, imported.importZedServer)
+
+	default:
+		return nil, fmt.Errorf("unsupported config format (expected servers, mcpServers or context_servers)")
+	}
+
+	return imported, nil
+}
+
+// isNativeConfig 判断是否为 go-mcp-cli 格式：servers 中的条目使用 transport 而不是 VS Code 的 type
+func isNativeConfig(doc map[string]any) bool {
+	servers, ok := doc["servers"].(map[string]any)
+	if !ok {
+		return false
+	}
+	if _, ok := doc["version"]; ok {
+		return true
+	}
+	for _, entry := range servers {
+		if fields, ok := entry.(map[string]any); ok {
+			if _, ok := fields["transport"]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// importEntries 按名称顺序导入 name -> 条目 形式的服务器配置
+func (im *Imported) importEntries(servers map[string]any, convert func(r *entryReader) *ServerConfig) {
+	names := make([]string, 0, len(servers))
+	for name := range servers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fields, ok := servers[name].(map[string]any)
+		if !ok {
+			im.warnf("server %s: expected an object, skipped", name)
+			continue
+		}
+		r := &entryReader{name: name, fields: fields, used: make(map[string]bool), im: im}
+		if server := convert(r); server != nil {
+			r.finish()
+			im.Config.Servers[name] = server
+		}
+	}
+}
+
+// importClaudeServer 转换 Claude Desktop / Cursor 条目
+func (im *Imported) importClaudeServer(r *entryReader) *ServerConfig {
+	server := &ClaudeServerConfig{
+		Type:    r.str("type"),
+		Command: r.str("command"),
+		Args:    r.strs("args"),
+		Env:     r.strMap("env"),
+		URL:     r.str("url"),
+		Headers: r.strMap("headers"),
+	}
+	if server.Type == "streamable-http" || server.Type == "streamableHttp" {
+		server.Type = "http"
+	}
+	if server.Type != "" && server.Type != "stdio" && server.Type != "sse" && server.Type != "http" {
+		im.warnf("server %s: unsupported type %q, skipped", r.name, server.Type)
+		return nil
+	}
+	return r.require(convertClaudeServer(r.name, server))
+}
+
+// importVSCodeServer 转换 VS Code 条目，type 缺省时根据 command/url 推断
+func (im *Imported) importVSCodeServer(r *entryReader) *ServerConfig {
+	return r.require(r.typed(r.str("type")))
+}
+
+// importZedServer 转换 Zed 条目，兼容旧版的 {"command": {"path", "args", "env"}} 写法
+func (im *Imported) importZedServer(r *entryReader) *ServerConfig {
+	if source := r.str("source"); source != "" && source != "custom" {
+		im.warnf("server %s: %s context servers are managed by Zed, skipped", r.name, source)
+		return nil
+	}
+	if legacy, ok := r.fields["command"].(map[string]any); ok {
+		r.used["command"] = true
+		nested := &entryReader{name: r.name, fields: legacy, used: make(map[string]bool), im: im}
+		server := &ServerConfig{
+			Name:      r.name,
+			Transport: "stdio",
+			Command:   nested.str("path"),
+			Args:      nested.strs("args"),
+			Env:       nested.strMap("env"),
+		}
+		nested.finish()
+		r.ignore("settings")
+		return r.require(server)
+	}
+	r.ignore("settings")
+	return r.require(r.typed(""))
+}
+
+// importContinueServers 转换 Continue 的 mcpServers 列表
+func (im *Imported) importContinueServers(servers []any) {
+	for i, entry := range servers {
+		fields, ok := entry.(map[string]any)
+		if !ok {
+			im.warnf("mcpServers[%d]: expected an object, skipped", i)
+			continue
+		}
+		name, _ := fields["name"].(string)
+		if uses, ok := fields["uses"].(string); ok {
+			im.warnf("mcpServers[%d]: hub block %s is not supported, skipped", i, uses)
+			continue
+		}
+		if name == "" {
+			im.warnf("mcpServers[%d]: missing name, skipped", i)
+			continue
+		}
+		if _, exists := im.Config.Servers[name]; exists {
+			im.warnf("server %s: duplicate name, skipped", name)
+			continue
+		}
+
+		r := &entryReader{name: name, fields: fields, used: map[string]bool{"name": true}, im: im}
+		server := r.typed(r.str("type"))
+		if server == nil {
+			continue
+		}
+		if options, ok := fields["requestOptions"].(map[string]any); ok {
+			r.used["requestOptions"] = true
+			nested := &entryReader{name: name, fields: options, used: make(map[string]bool), im: im}
+			server.Headers = nested.strMap("headers")
+			nested.finish()
+		}
+		if server = r.require(server); server != nil {
+			r.finish()
+			im.Config.Servers[name] = server
+		}
+	}
+}
+
+func (im *Imported) warnf(format string, args ...any) {
+	im.Warnings = append(im.Warnings, fmt.Sprintf(format, args...))
+}
+
+// entryReader 读取单个服务器条目，记录已使用的字段以便报告不支持的字段
+type entryReader struct {
+	name   string
+	fields map[string]any
+	used   map[string]bool
+	im     *Imported
+}
+
+// typed 根据 type 字段（stdio、sse、http 或 streamable-http）读取条目，type 为空时推断
+func (r *entryReader) typed(kind string) *ServerConfig {
+	server := &ServerConfig{Name: r.name}
+	switch kind {
+	case "stdio", "sse", "http":
+		server.Transport = kind
+	case "streamable-http", "streamableHttp":
+		server.Transport = "http"
+	case "":
+	default:
+		r.im.warnf("server %s: unsupported type %q, skipped", r.name, kind)
+		return nil
+	}
+
+	if _, ok := r.fields["command"]; ok && (server.Transport == "" || server.Transport == "stdio") {
+		server.Transport = "stdio"
+		server.Command = r.str("command")
+		server.Args = r.strs("args")
+		server.Env = r.strMap("env")
+		server.Cwd = r.str("cwd")
+	} else {
+		server.URL = r.str("url")
+		server.Headers = r.strMap("headers")
+		if server.Transport == "" && server.URL != "" {
+			server.Transport = detectTransportType(server.URL)
+		}
+	}
+	return server
+}
+
+// require 检查条目是否提供了命令或 URL，缺失时跳过并报告
+func (r *entryReader) require(server *ServerConfig) *ServerConfig {
+	if server == nil {
+		return nil
+	}
+	if server.Transport == "stdio" && server.Command == "" || server.Transport != "stdio" && server.URL == "" {
+		r.im.warnf("server %s: missing command or url, skipped", r.name)
+		return nil
+	}
+	return server
+}
+
+// ignore 将字段标记为已处理（宿主专用且无需提示的字段）
+func (r *entryReader) ignore(keys ...string) {
+	for _, key := range keys {
+		r.used[key] = true
+	}
+}
+
+// finish 报告未处理的字段
+func (r *entryReader) finish() {
+	var unknown []string
+	for key := range r.fields {
+		if !r.used[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	for _, key := range unknown {
+		r.im.warnf("server %s: unsupported field %q ignored", r.name, key)
+	}
+}
+
+func (r *entryReader) str(key string) string {
+	value, ok := r.fields[key]
+	if !ok {
+		return ""
+	}
+	r.used[key] = true
+	s, ok := scalarString(value)
+	if !ok {
+		r.im.warnf("server %s: field %q should be a string, ignored", r.name, key)
+	}
+	return s
+}
+
+func (r *entryReader) strs(key string) []string {
+	value, ok := r.fields[key]
+	if !ok {
+		return nil
+	}
+	r.used[key] = true
+	items, ok := value.([]any)
+	if !ok {
+		r.im.warnf("server %s: field %q should be a list, ignored", r.name, key)
+		return nil
+	}
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		s, ok := scalarString(item)
+		if !ok {
+			r.im.warnf("server %s: field %q contains a non-string value, ignored", r.name, key)
+			continue
+		}
+		result = append(result, s)
+	}
+	return result
+}
+
+func (r *entryReader) strMap(key string) map[string]string {
+	value, ok := r.fields[key]
+	if !ok {
+		return nil
+	}
+	r.used[key] = true
+	items, ok := value.(map[string]any)
+	if !ok {
+		r.im.warnf("server %s: field %q should be an object, ignored", r.name, key)
+		return nil
+	}
+	result := make(map[string]string, len(items))
+	for k, item := range items {
+		s, ok := scalarString(item)
+		if !ok {
+			r.im.warnf("server %s: %s.%s should be a string, ignored", r.name, key, k)
+			continue
+		}
+		result[k] = s
+	}
+	return result
+}
+
+// scalarString 将字符串、数字和布尔值转换为字符串（YAML 中 PORT: 8080 之类的写法）
+func scalarString(value any) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case float64, int, bool:
+		return fmt.Sprint(v), true
+	default:
+		return "", false
+	}
+}
+
+// stripJSONC 去掉 JSONC 中的注释和尾随逗号，字符串内容保持不变
+func stripJSONC(data []byte) []byte {
+	return stripTrailingCommas(stripComments(data))
+}
+
+// stripComments 去掉 // 和 /* */ 注释
+func stripComments(data []byte) []byte {
+	out := make([]byte, 0, len(data))
+	inString := false
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case inString:
+			out = append(out, c)
+			if c == '\\' && i+1 < len(data) {
+				i++
+				out = append(out, data[i])
+			} else if c == '"' {
+				inString = false
+			}
+		case c == '"':
+			inString = true
+			out = append(out, c)
+		case c == '/' && i+1 < len(data) && data[i+1] == '/':
+			for i+1 < len(data) && data[i+1] != '\n' {
+				i++
+			}
+		case c == '/' && i+1 < len(data) && data[i+1] == '*':
+			// 保留注释中的换行，错误信息中的行号与原文件一致
+			out = append(out, ' ')
+			for i += 2; i+1 < len(data) && !(data[i] == '*' && data[i+1] == '/'); i++ {
+				if data[i] == '\n' {
+					out = append(out, '\n')
+				}
+			}
+			i++
+		default:
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// stripTrailingCommas 去掉紧跟 } 或 ] 的逗号
+func stripTrailingCommas(data []byte) []byte {
+	out := make([]byte, 0, len(data))
+	inString := false
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case inString:
+			out = append(out, c)
+			if c == '\\' && i+1 < len(data) {
+				i++
+				out = append(out, data[i])
+			} else if c == '"' {
+				inString = false
+			}
+			continue
+		case c == '"':
+			inString = true
+		case c == ',':
+			j := i + 1
+			for j < len(data) && strings.ContainsRune(" \t\r\n", rune(data[j])) {
+				j++
+			}
+			if j < len(data) && (data[j] == '}' || data[j] == ']') {
+				continue
+			}
+		}
+		out = append(out, c)
+	}
+	return out
+}
@@ -0,0 +1,177 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// Copyright 2025 MCP CLI Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+func TestStripJSONC(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "plain JSON", in: `{"a": 1}`, want: `{"a": 1}`},
+		{name: "line comment", in: "{\"a\": 1 // one\n}", want: "{\"a\": 1 \n}"},
+		{name: "block comment keeps newlines", in: "{/* a\nb */\"a\": 1}", want: "{ \n\"a\": 1}"},
+		{name: "trailing comma in object", in: `{"a": 1,}`, want: `{"a": 1}`},
+		{name: "trailing comma in array", in: "[1, 2,\n]", want: "[1, 2\n]"},
+		{name: "comment markers in string", in: `{"url": "http://x/*y*/"}`, want: `{"url": "http://x/*y*/"}`},
+		{name: "comma before brace in string", in: `{"s": ",}"}`, want: `{"s": ",}"}`},
+		{name: "escaped quote in string", in: `{"s": "a\"//b"}`, want: `{"s": "a\"//b"}`},
+		{name: "comma then comment then brace", in: "{\"a\": 1, // x\n}", want: "{\"a\": 1 \n}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(stripJSONC([]byte(tt.in))); got != tt.want {
+				t.Errorf("stripJSONC(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseHostConfig(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      string
+		format    string
+		servers   map[string]string // 服务器名 -> transport
+		warnings  int
+		wantError string
+	}{
+		{
+			name:    "go-mcp-cli",
+			data:    `{"version": "1.0.0", "servers": {"t": {"transport": "stdio", "command": "t"}}}`,
+			format:  FormatGoMCPCLI,
+			servers: map[string]string{"t": "stdio"},
+		},
+		{
+			name:    "go-mcp-cli without version",
+			data:    `{"servers": {"t": {"transport": "http", "url": "https://x"}}}`,
+			format:  FormatGoMCPCLI,
+			servers: map[string]string{"t": "http"},
+		},
+		{
+			name:      "go-mcp-cli unknown field",
+			data:      "{\"servers\": {\"t\": {\n\"transport\": \"stdio\", \"comand\": \"t\"}}}",
+			wantError: "line 2",
+		},
+		{
+			name:    "go-mcp-cli as YAML",
+			data:    "version: 1.0.0\nservers:\n  t:\n    transport: stdio\n    command: t\n",
+			format:  FormatGoMCPCLI,
+			servers: map[string]string{"t": "stdio"},
+		},
+		{
+			name:    "claude desktop",
+			data:    `{"mcpServers": {"a": {"command": "a"}, "b": {"url": "https://x/sse"}, "c": {"type": "http", "url": "https://x/sse"}}}`,
+			format:  FormatClaudeDesktop,
+			servers: map[string]string{"a": "stdio", "b": "sse", "c": "http"},
+		},
+		{
+			name: "vscode mcp.json with comments",
+			data: `{
+				// workspace servers
+				"inputs": [],
+				"servers": {
+					"a": {"type": "stdio", "command": "a"},
+					"b": {"type": "http", "url": "https://x",},
+				},
+			}`,
+			format:   FormatVSCode,
+			servers:  map[string]string{"a": "stdio", "b": "http"},
+			warnings: 1,
+		},
+		{
+			name:    "vscode settings.json",
+			data:    `{"editor.tabSize": 2, "mcp": {"servers": {"a": {"command": "a"}}}}`,
+			format:  FormatVSCode,
+			servers: map[string]string{"a": "stdio"},
+		},
+		{
+			name:    "zed",
+			data:    `{"context_servers": {"a": {"source": "custom", "command": "a", "args": ["x"]}}}`,
+			format:  FormatZed,
+			servers: map[string]string{"a": "stdio"},
+		},
+		{
+			name:    "continue",
+			data:    "name: x\nmcpServers:\n  - name: a\n    command: a\n  - name: b\n    type: streamable-http\n    url: https://x\n",
+			format:  FormatContinue,
+			servers: map[string]string{"a": "stdio", "b": "http"},
+		},
+		{
+			name:      "unknown format",
+			data:      `{"other": {}}`,
+			wantError: "unsupported config format",
+		},
+		{
+			name:      "invalid JSON",
+			data:      "{\n\"servers\": [\n}",
+			wantError: "failed to parse config",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			imported, err := ParseHostConfig([]byte(tt.data))
+			if tt.wantError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantError) {
+					t.Fatalf("ParseHostConfig() error = %v, want %q", err, tt.wantError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseHostConfig(): %v", err)
+			}
+			if imported.Format != tt.format {
+				t.Errorf("format = %q, want %q", imported.Format, tt.format)
+			}
+			if len(imported.Warnings) != tt.warnings {
+				t.Errorf("warnings = %q, want %d", imported.Warnings, tt.warnings)
+			}
+			if len(imported.Config.Servers) != len(tt.servers) {
+				t.Errorf("servers = %d, want %d", len(imported.Config.Servers), len(tt.servers))
+			}
+			for name, transport := range tt.servers {
+				server := imported.Config.Servers[name]
+				if server == nil {
+					t.Errorf("server %q missing", name)
+					continue
+				}
+				if server.Transport != transport {
+					t.Errorf("server %q transport = %q, want %q", name, server.Transport, transport)
+				}
+				if server.Name != name {
+					t.Errorf("server %q name = %q", name, server.Name)
+				}
+			}
+		})
+	}
+}
+
+func TestParseHostConfigLineError(t *testing.T) {
+	_, err := ParseHostConfig([]byte("{\n  \"servers\": {\n    \"t\": {\"transport\": \"stdio\",, }\n  }\n}"))
+	var lineErr *LineError
+	if !errors.As(err, &lineErr) {
+		t.Fatalf("ParseHostConfig() error = %v, want *LineError", err)
+	}
+	if lineErr.Line != 3 {
+		t.Errorf("line = %d, want 3", lineErr.Line)
+	}
+}
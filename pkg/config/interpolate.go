@@ -0,0 +1,161 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Copyright 2025 MCP CLI Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// interpolationPattern 配置值中的 ${...} 引用
+var interpolationPattern = regexp.MustCompile(`\$\{([^}]*)\}`)
+
+// varNamePattern 合法的环境变量名
+var varNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// UnresolvedError 服务器配置中存在无法解析的引用
+type UnresolvedError struct {
+	Server     string
+	References []string
+}
+
+func (e *UnresolvedError) Error() string {
+	return fmt.Sprintf("unresolved variables in server %s: %s", e.Server, strings.Join(e.References, ", "))
+}
+
+// interpolateServer 展开服务器配置中 Command、Args、Env、URL、Headers、Cwd、TLS 文件路径
+// 以及 auth 的 clientId、clientSecret、authorizationServer 中的引用，
+// 返回展开后的副本和无法解析的引用列表。
+//
+// 支持的写法：
+//   - ${VAR} 或 ${env:VAR}：环境变量，未设置时无法解析
+//   - ${VAR:-default}：环境变量，未设置或为空时使用默认值
+//   - ${file:/path}：文件内容（去掉末尾换行），支持 ~ 表示用户主目录
+//
+// ${secret:name} 保持不变，在连接时由密钥存储解析。
+func interpolateServer(server *ServerConfig) (*ServerConfig, []string) {
+	var unresolved []string
+	expand := func(s string) string {
+		return interpolate(s, &unresolved)
+	}
+
+	resolved := *server
+	resolved.Command = expand(server.Command)
+	resolved.URL = expand(server.URL)
+	resolved.Cwd = expandHome(expand(server.Cwd))
+	if server.Args != nil {
+		resolved.Args = make([]string, len(server.Args))
+		for i, arg := range server.Args {
+			resolved.Args[i] = expand(arg)
+		}
+	}
+	resolved.Env = interpolateMap(server.Env, expand)
+	resolved.Headers = interpolateMap(server.Headers, expand)
+	if server.TLS != nil {
+		tls := *server.TLS
+		tls.CAFile = expandHome(expand(tls.CAFile))
+		tls.CertFile = expandHome(expand(tls.CertFile))
+		tls.KeyFile = expandHome(expand(tls.KeyFile))
+		resolved.TLS = &tls
+	}
+	if server.Auth != nil {
+		auth := *server.Auth
+		auth.ClientID = expand(auth.ClientID)
+		auth.ClientSecret = expand(auth.ClientSecret)
+		auth.AuthorizationServer = expand(auth.AuthorizationServer)
+		resolved.Auth = &auth
+	}
+
+	sort.Strings(unresolved)
+	return &resolved, slices.Compact(unresolved)
+}
+
+// ResolveServer 展开单个服务器配置中的引用，规则与 ConfigManager.ResolvedServer 相同，
+// 用于尚未保存的配置（如 login 用命令行参数修改后的配置）
+func ResolveServer(server *ServerConfig) (*ServerConfig, error) {
+	resolved, unresolved := interpolateServer(server)
+	if len(unresolved) > 0 {
+		return nil, &UnresolvedError{Server: server.Name, References: unresolved}
+	}
+	return resolved, nil
+}
+
+func interpolateMap(values map[string]string, expand func(string) string) map[string]string {
+	if values == nil {
+		return nil
+	}
+	result := make(map[string]string, len(values))
+	for key, value := range values {
+		result[key] = expand(value)
+	}
+	return result
+}
+
+// interpolate 展开字符串中的引用，无法解析的引用原样保留并记录到 unresolved
+func interpolate(s string, unresolved *[]string) string {
+	if !strings.Contains(s, "${") {
+		return s
+	}
+
+	return interpolationPattern.ReplaceAllStringFunc(s, func(ref string) string {
+		expr := ref[2 : len(ref)-1]
+
+		switch {
+		case strings.HasPrefix(expr, "secret:"):
+			return ref
+
+		case strings.HasPrefix(expr, "file:"):
+			path := expandHome(strings.TrimPrefix(expr, "file:"))
+			data, err := os.ReadFile(path)
+			if err != nil {
+				*unresolved = append(*unresolved, ref+" (cannot read file)")
+				return ref
+			}
+			return strings.TrimRight(string(data), "\r\n")
+		}
+
+		name, fallback, hasDefault := strings.Cut(strings.TrimPrefix(expr, "env:"), ":-")
+		if !varNamePattern.MatchString(name) {
+			*unresolved = append(*unresolved, ref+" (invalid reference)")
+			return ref
+		}
+		value, ok := os.LookupEnv(name)
+		if hasDefault && value == "" {
+			return fallback
+		}
+		if !ok {
+			*unresolved = append(*unresolved, ref)
+			return ref
+		}
+		return value
+	})
+}
+
+// expandHome 将开头的 ~ 替换为用户主目录
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
@@ -0,0 +1,141 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// Copyright 2025 MCP CLI Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+func TestInterpolate(t *testing.T) {
+	dir := t.TempDir()
+	tokenFile := filepath.Join(dir, "token")
+	if err := os.WriteFile(tokenFile, []byte("from-file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("MCP_TEST_SET", "value")
+	t.Setenv("MCP_TEST_EMPTY", "")
+	unsetenv(t, "MCP_TEST_UNSET")
+
+	tests := []struct {
+		in         string
+		want       string
+		unresolved []string
+	}{
+		{in: "no references", want: "no references"},
+		{in: "${MCP_TEST_SET}", want: "value"},
+		{in: "${env:MCP_TEST_SET}", want: "value"},
+		{in: "a-${MCP_TEST_SET}-b-${env:MCP_TEST_SET}", want: "a-value-b-value"},
+		{in: "${MCP_TEST_EMPTY}", want: ""},
+		{in: "${MCP_TEST_UNSET:-fallback}", want: "fallback"},
+		{in: "${MCP_TEST_EMPTY:-fallback}", want: "fallback"},
+		{in: "${env:MCP_TEST_SET:-fallback}", want: "value"},
+		{in: "${MCP_TEST_UNSET}", want: "${MCP_TEST_UNSET}", unresolved: []string{"${MCP_TEST_UNSET}"}},
+		{in: "${not-a-name}", want: "${not-a-name}", unresolved: []string{"${not-a-name} (invalid reference)"}},
+		{in: "${file:" + tokenFile + "}", want: "from-file"},
+		{in: "${file:" + filepath.Join(dir, "missing") + "}", want: "${file:" + filepath.Join(dir, "missing") + "}",
+			unresolved: []string{"${file:" + filepath.Join(dir, "missing") + "} (cannot read file)"}},
+		{in: "Bearer ${secret:token}", want: "Bearer ${secret:token}"},
+	}
+	for _, tt := range tests {
+		var unresolved []string
+		got := interpolate(tt.in, &unresolved)
+		if got != tt.want {
+			t.Errorf("interpolate(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+		if !reflect.DeepEqual(unresolved, tt.unresolved) {
+			t.Errorf("interpolate(%q) unresolved = %q, want %q", tt.in, unresolved, tt.unresolved)
+		}
+	}
+}
+
+func TestInterpolateServer(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("MCP_TEST_HOST", "example.com")
+	t.Setenv("MCP_TEST_TOKEN", "t0k")
+	t.Setenv("MCP_TEST_CLIENT", "cid")
+	unsetenv(t, "MCP_TEST_UNSET")
+
+	// 每次调用返回新的副本，用于检查 interpolateServer 没有修改输入
+	newServer := func() *ServerConfig {
+		return &ServerConfig{
+			Name:      "s",
+			Transport: "http",
+			Command:   "${MCP_TEST_HOST}",
+			Args:      []string{"--host", "${MCP_TEST_HOST}"},
+			Env:       map[string]string{"TOKEN": "${MCP_TEST_TOKEN}"},
+			URL:       "https://${MCP_TEST_HOST}/mcp",
+			Headers:   map[string]string{"Authorization": "Bearer ${secret:api}", "X-Token": "${MCP_TEST_TOKEN}"},
+			Cwd:       "~/work",
+			TLS:       &TLSConfig{CAFile: "~/ca.pem", ServerName: "${MCP_TEST_HOST}"},
+			Auth:      &AuthConfig{ClientID: "${MCP_TEST_CLIENT}", ClientSecret: "${secret:cs}", AuthorizationServer: "https://${MCP_TEST_HOST}"},
+		}
+	}
+	server := newServer()
+
+	resolved, unresolved := interpolateServer(server)
+	if len(unresolved) != 0 {
+		t.Fatalf("unresolved = %q", unresolved)
+	}
+	want := &ServerConfig{
+		Name:      "s",
+		Transport: "http",
+		Command:   "example.com",
+		Args:      []string{"--host", "example.com"},
+		Env:       map[string]string{"TOKEN": "t0k"},
+		URL:       "https://example.com/mcp",
+		Headers:   map[string]string{"Authorization": "Bearer ${secret:api}", "X-Token": "t0k"},
+		Cwd:       filepath.Join(home, "work"),
+		// serverName 不做展开
+		TLS:  &TLSConfig{CAFile: filepath.Join(home, "ca.pem"), ServerName: "${MCP_TEST_HOST}"},
+		Auth: &AuthConfig{ClientID: "cid", ClientSecret: "${secret:cs}", AuthorizationServer: "https://example.com"},
+	}
+	if !reflect.DeepEqual(resolved, want) {
+		t.Errorf("interpolateServer() = %+v, want %+v", resolved, want)
+	}
+	if !reflect.DeepEqual(server, newServer()) {
+		t.Errorf("interpolateServer() modified its input: %+v", server)
+	}
+}
+
+func TestResolveServerUnresolved(t *testing.T) {
+	unsetenv(t, "MCP_TEST_UNSET")
+	server := &ServerConfig{
+		Name:    "s",
+		Command: "${MCP_TEST_UNSET}",
+		Args:    []string{"${MCP_TEST_UNSET}", "${bad name}"},
+	}
+
+	_, err := ResolveServer(server)
+	var unresolvedErr *UnresolvedError
+	if !errors.As(err, &unresolvedErr) {
+		t.Fatalf("ResolveServer() error = %v, want *UnresolvedError", err)
+	}
+	want := []string{"${MCP_TEST_UNSET}", "${bad name} (invalid reference)"}
+	if unresolvedErr.Server != "s" || !reflect.DeepEqual(unresolvedErr.References, want) {
+		t.Errorf("ResolveServer() error = %+v, want references %q", unresolvedErr, want)
+	}
+}
+
+// unsetenv 在测试期间删除环境变量，结束后恢复原值
+func unsetenv(t *testing.T, key string) {
+	t.Helper()
+	t.Setenv(key, "")
+	os.Unsetenv(key)
+}
@@ -0,0 +1,148 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/justinwongcn/go-mcp-cli/pkg/fileutil"
+)
+
+// Copyright 2025 MCP CLI Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// maxBackups 保留的历史版本数：config.json.bak 为上一版，config.json.bak.1、.bak.2 依次更早
+const maxBackups = 3
+
+// update 在文件锁内重新读取配置层、应用修改并原子写回。
+//
+// 其他进程可能在本进程加载配置之后修改了文件，因此修改总是应用在锁内重新读取的内容上，
+// 而不是加载时的快照，并发的 mcp-cli add 不会互相覆盖。
+// 其他宿主格式的配置层（如 VS Code 的 settings.json）只读，写回会丢失其中的其他设置。
+func (cm *ConfigManager) update(l *layer, mutate func(config *Config) error) error {
+	if l.format != FormatGoMCPCLI {
+		return readOnlyError(l.path, l.format)
+	}
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	release, err := fileutil.Lock(l.path + ".lock")
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	// 配置文件是符号链接（如指向 dotfiles 仓库）时写入链接目标，保留链接本身
+	path, err := resolveSymlinks(l.path)
+	if err != nil {
+		return fmt.Errorf("failed to resolve config path: %w", err)
+	}
+
+	config := newConfig()
+	previous, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		imported, err := ParseHostConfig(previous)
+		if err != nil {
+			return fmt.Errorf("%s: %w", l.path, err)
+		}
+		// 加载之后文件可能被替换成了其他格式
+		if imported.Format != FormatGoMCPCLI {
+			return readOnlyError(l.path, imported.Format)
+		}
+		config = imported.Config
+		if config.Servers == nil {
+			config.Servers = make(map[string]*ServerConfig)
+		}
+	case os.IsNotExist(err):
+		previous = nil
+	default:
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+
+	if err := mutate(config); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+	if !bytes.Equal(data, previous) {
+		if previous != nil {
+			if err := rotateBackups(l.path, previous); err != nil {
+				return fmt.Errorf("failed to back up config: %w", err)
+			}
+		}
+		// 配置中可能包含请求头和环境变量等敏感信息，仅当前用户可读写
+		if err := fileutil.WriteAtomic(path, data, 0600); err != nil {
+			return fmt.Errorf("failed to write config: %w", err)
+		}
+	}
+
+	l.config = config
+	cm.merge()
+	return nil
+}
+
+// readOnlyError 返回写入其他宿主格式的配置文件时的错误
+func readOnlyError(path, format string) error {
+	return fmt.Errorf("%s is a %s config and is read-only; use 'mcp-cli import %s' to copy its servers into a go-mcp-cli config", path, format, path)
+}
+
+// resolveSymlinks 返回 path 最终指向的文件。path 不存在时原样返回；
+// 链接目标不存在时返回目标路径，写入会创建目标文件。
+func resolveSymlinks(path string) (string, error) {
+	for range 255 {
+		info, err := os.Lstat(path)
+		if os.IsNotExist(err) {
+			return path, nil
+		}
+		if err != nil {
+			return "", err
+		}
+		if info.Mode()&os.ModeSymlink == 0 {
+			return path, nil
+		}
+		target, err := os.Readlink(path)
+		if err != nil {
+			return "", err
+		}
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(filepath.Dir(path), target)
+		}
+		path = target
+	}
+	return "", fmt.Errorf("too many levels of symbolic links: %s", path)
+}
+
+// rotateBackups 将 previous 保存为 path.bak，已有的备份依次后移，最多保留 maxBackups 份
+func rotateBackups(path string, previous []byte) error {
+	for i := maxBackups - 1; i > 0; i-- {
+		if err := os.Rename(backupPath(path, i-1), backupPath(path, i)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return fileutil.WriteAtomic(backupPath(path, 0), previous, 0600)
+}
+
+func backupPath(path string, index int) string {
+	if index == 0 {
+		return path + ".bak"
+	}
+	return fmt.Sprintf("%s.bak.%d", path, index)
+}
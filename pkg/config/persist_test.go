@@ -0,0 +1,216 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Copyright 2025 MCP CLI Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+func TestUpdateServerKeepsConcurrentEdits(t *testing.T) {
+	root := isolateConfig(t)
+	path := filepath.Join(root, "config.json")
+	writeFile(t, path, `{"version": "1.0.0", "servers": {"s": {"transport": "stdio", "command": "x"}}}`)
+
+	// 两个配置管理器都在对方修改之前加载，模拟两个同时运行的 mcp-cli set
+	a, err := NewConfigManager(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := NewConfigManager(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := a.UpdateServer("s", func(s *ServerConfig) error { return SetField(s, "cwd", []string{"/a"}) }); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.UpdateServer("s", func(s *ServerConfig) error { return SetField(s, "command", []string{"y"}) }); err != nil {
+		t.Fatal(err)
+	}
+
+	// 同时修改同一个文件的不同字段
+	const n = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			cm, err := NewConfigManager(path)
+			if err == nil {
+				err = cm.UpdateServer("s", func(s *ServerConfig) error {
+					return SetField(s, fmt.Sprintf("env.K%d", i), []string{"v"})
+				})
+			}
+			errs <- err
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cm, err := NewConfigManager(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := cm.GetServer("s")
+	if server.Cwd != "/a" || server.Command != "y" {
+		t.Errorf("cwd = %q, command = %q, want both edits kept", server.Cwd, server.Command)
+	}
+	if len(server.Env) != n {
+		t.Errorf("env = %v, want %d keys", server.Env, n)
+	}
+}
+
+func TestUpdateServerMissing(t *testing.T) {
+	root := isolateConfig(t)
+	path := filepath.Join(root, "config.json")
+	writeFile(t, path, `{"version": "1.0.0", "servers": {"s": {"transport": "stdio", "command": "x"}}}`)
+	cm, err := NewConfigManager(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 加载之后被其他进程删除
+	other, _ := NewConfigManager(path)
+	if _, err := other.RemoveServer("s"); err != nil {
+		t.Fatal(err)
+	}
+	called := false
+	err = cm.UpdateServer("s", func(*ServerConfig) error { called = true; return nil })
+	if err == nil || called {
+		t.Errorf("UpdateServer() of removed server = %v, called = %v", err, called)
+	}
+	if err := cm.UpdateServer("nope", func(*ServerConfig) error { return nil }); err == nil {
+		t.Error("UpdateServer() of unknown server succeeded")
+	}
+}
+
+func TestUpdateBackupsAndMode(t *testing.T) {
+	root := isolateConfig(t)
+	path := filepath.Join(root, "config.json")
+	cm, err := NewConfigManager(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var versions []string
+	for i := 0; i < 5; i++ {
+		if err := cm.AddServer(fmt.Sprintf("s%d", i), &ServerConfig{Transport: "stdio", Command: "x"}); err != nil {
+			t.Fatal(err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		versions = append(versions, string(data))
+	}
+
+	// 写入相同的内容不产生新的备份
+	if err := cm.AddServer("s4", &ServerConfig{Transport: "stdio", Command: "x"}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: path, want: versions[4]},
+		{path: path + ".bak", want: versions[3]},
+		{path: path + ".bak.1", want: versions[2]},
+		{path: path + ".bak.2", want: versions[1]},
+	}
+	for _, tt := range tests {
+		data, err := os.ReadFile(tt.path)
+		if err != nil {
+			t.Errorf("%s: %v", filepath.Base(tt.path), err)
+			continue
+		}
+		if string(data) != tt.want {
+			t.Errorf("%s has the wrong version", filepath.Base(tt.path))
+		}
+		info, err := os.Stat(tt.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if perm := info.Mode().Perm(); perm != 0600 {
+			t.Errorf("%s mode = %o, want 600", filepath.Base(tt.path), perm)
+		}
+	}
+	if _, err := os.Stat(path + ".bak.3"); !os.IsNotExist(err) {
+		t.Errorf("found more than %d backups", maxBackups)
+	}
+	matches, _ := filepath.Glob(filepath.Join(root, ".config.json.tmp-*"))
+	if len(matches) > 0 {
+		t.Errorf("temporary files left behind: %v", matches)
+	}
+}
+
+func TestUpdateFollowsSymlink(t *testing.T) {
+	root := isolateConfig(t)
+	target := filepath.Join(root, "dotfiles", "mcp.json")
+	writeFile(t, target, `{"version": "1.0.0", "servers": {}}`)
+	link := filepath.Join(root, "config.json")
+	if err := os.Symlink(filepath.Join("dotfiles", "mcp.json"), link); err != nil {
+		t.Skip("symlinks not supported:", err)
+	}
+
+	cm, err := NewConfigManager(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cm.AddServer("s", &ServerConfig{Transport: "stdio", Command: "x"}); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Lstat(link)
+	if err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Fatalf("config.json is no longer a symlink: %v", err)
+	}
+	data, err := os.ReadFile(target)
+	if err != nil || !strings.Contains(string(data), `"s"`) {
+		t.Errorf("link target was not updated: %s, %v", data, err)
+	}
+}
+
+func TestUpdateReadOnlyLayer(t *testing.T) {
+	root := isolateConfig(t)
+	path := filepath.Join(root, "settings.json")
+	original := `{"editor.tabSize": 2, "mcp": {"servers": {"s": {"command": "x"}}}}`
+	writeFile(t, path, original)
+
+	cm, err := NewConfigManager(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cm.GetServer("s") == nil {
+		t.Fatal("server from VS Code settings was not loaded")
+	}
+	err = cm.UpdateServer("s", func(s *ServerConfig) error { return SetField(s, "command", []string{"y"}) })
+	if err == nil || !strings.Contains(err.Error(), "read-only") {
+		t.Errorf("UpdateServer() on vscode config error = %v, want read-only", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != original {
+		t.Errorf("read-only config was modified: %s", data)
+	}
+}
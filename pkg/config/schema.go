@@ -0,0 +1,209 @@
+package config
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Copyright 2025 MCP CLI Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// JSONSchema go-mcp-cli 配置文件的 JSON Schema，可在配置中通过 "$schema" 引用
+//
+//go:embed schema.json
+var JSONSchema []byte
+
+// SchemaURL JSON Schema 的发布地址
+const SchemaURL = "https://raw.githubusercontent.com/justinwongcn/go-mcp-cli/main/pkg/config/schema.json"
+
+// LineError 配置文件中某一行的错误
+type LineError struct {
+	Line    int
+	Message string
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+}
+
+// objectSchema 对象允许的字段。fields 为空时对象是任意键的映射，每个值使用 values 检查。
+type objectSchema struct {
+	fields map[string]*objectSchema
+	values *objectSchema
+}
+
+// configSchema 由 Config 的 json 标签生成，新增字段无需同步修改
+var configSchema = schemaOf(reflect.TypeOf(Config{}))
+
+// schemaOf 根据结构体的 json 标签生成字段检查规则，非对象类型返回 nil（不检查）
+func schemaOf(t reflect.Type) *objectSchema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		schema := &objectSchema{fields: make(map[string]*objectSchema)}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" || !field.IsExported() {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			schema.fields[name] = schemaOf(field.Type)
+		}
+		return schema
+
+	case reflect.Map:
+		if values := schemaOf(t.Elem()); values != nil {
+			return &objectSchema{values: values}
+		}
+	}
+	return nil
+}
+
+// checkFields 逐个读取 JSON 记号，报告未知字段和重复的键（包括重复的服务器名）。
+// json.Unmarshal 会静默忽略前者、用后出现的值覆盖后者。
+func checkFields(data []byte) error {
+	c := &fieldChecker{data: data, dec: json.NewDecoder(bytes.NewReader(data))}
+	return c.value(configSchema, "")
+}
+
+type fieldChecker struct {
+	data []byte
+	dec  *json.Decoder
+}
+
+func (c *fieldChecker) value(schema *objectSchema, path string) error {
+	tok, err := c.dec.Token()
+	if err != nil {
+		return err
+	}
+
+	switch tok {
+	case json.Delim('{'):
+		seen := make(map[string]bool)
+		for c.dec.More() {
+			tok, err := c.dec.Token()
+			if err != nil {
+				return err
+			}
+			key := tok.(string)
+			if seen[key] {
+				return c.errorf("duplicate key %q in %s", key, describePath(path))
+			}
+			seen[key] = true
+
+			var child *objectSchema
+			if schema != nil {
+				if schema.fields != nil {
+					field, known := schema.fields[key]
+					if !known {
+						return c.errorf("unknown field %q in %s%s", key, describePath(path), suggestField(key, schema.fields))
+					}
+					child = field
+				} else {
+					child = schema.values
+				}
+			}
+
+			childPath := key
+			if path != "" {
+				childPath = path + "." + key
+			}
+			if err := c.value(child, childPath); err != nil {
+				return err
+			}
+		}
+		_, err = c.dec.Token()
+		return err
+
+	case json.Delim('['):
+		for c.dec.More() {
+			if err := c.value(nil, path); err != nil {
+				return err
+			}
+		}
+		_, err = c.dec.Token()
+		return err
+	}
+	return nil
+}
+
+// errorf 返回指向当前读取位置所在行的错误
+func (c *fieldChecker) errorf(format string, args ...any) error {
+	return &LineError{Line: lineAt(c.data, c.dec.InputOffset()), Message: fmt.Sprintf(format, args...)}
+}
+
+// lineAt 返回偏移量所在的行号（从 1 开始）
+func lineAt(data []byte, offset int64) int {
+	offset = min(offset, int64(len(data)))
+	return bytes.Count(data[:offset], []byte("\n")) + 1
+}
+
+func describePath(path string) string {
+	if path == "" {
+		return "config"
+	}
+	return path
+}
+
+// suggestField 为拼写错误的字段给出最接近的已知字段
+func suggestField(key string, fields map[string]*objectSchema) string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	best, bestDistance := "", 3
+	for _, name := range names {
+		if d := editDistance(strings.ToLower(key), strings.ToLower(name)); d < bestDistance {
+			best, bestDistance = name, d
+		}
+	}
+	if best != "" {
+		return fmt.Sprintf(" (did you mean %q?)", best)
+	}
+	return fmt.Sprintf(" (known fields: %s)", strings.Join(names, ", "))
+}
+
+// editDistance 计算两个字符串的编辑距离
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,159 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// Copyright 2025 MCP CLI Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+func TestCheckFields(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		line    int // 0 表示没有错误
+		message string
+	}{
+		{
+			name: "valid",
+			data: `{
+  "$schema": "` + SchemaURL + `",
+  "version": "1.0.0",
+  "servers": {
+    "a": {"transport": "stdio", "command": "x", "env": {"ANY_KEY": "v"}, "args": ["--x", {"free": 1}]},
+    "b": {"transport": "http", "url": "https://x", "auth": {"clientId": "c", "scopes": ["s"]}}
+  }
+}`,
+		},
+		{
+			name: "unknown top-level field",
+			data: `{
+  "version": "1.0.0",
+  "server": {}
+}`,
+			line:    3,
+			message: `unknown field "server" in config (did you mean "servers"?)`,
+		},
+		{
+			name: "typo in server field",
+			data: `{
+  "servers": {
+    "a": {
+      "transport": "stdio",
+      "comand": "x"
+    }
+  }
+}`,
+			line:    5,
+			message: `unknown field "comand" in servers.a (did you mean "command"?)`,
+		},
+		{
+			name: "unknown nested field lists known fields",
+			data: `{
+  "servers": {
+    "a": {
+      "transport": "http",
+      "tls": {"verify": false}
+    }
+  }
+}`,
+			line:    5,
+			message: `unknown field "verify" in servers.a.tls (known fields: caFile, certFile, insecureSkipVerify, keyFile, serverName)`,
+		},
+		{
+			name: "duplicate server name",
+			data: `{
+  "servers": {
+    "a": {"transport": "stdio", "command": "x"},
+
+    "a": {"transport": "stdio", "command": "y"}
+  }
+}`,
+			line:    5,
+			message: `duplicate key "a" in servers`,
+		},
+		{
+			name: "duplicate env key",
+			data: `{
+  "servers": {
+    "a": {"transport": "stdio", "command": "x", "env": {
+      "K": "1",
+      "K": "2"
+    }}
+  }
+}`,
+			line:    5,
+			message: `duplicate key "K" in servers.a.env`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkFields([]byte(tt.data))
+			if tt.line == 0 {
+				if err != nil {
+					t.Fatalf("checkFields(): %v", err)
+				}
+				return
+			}
+			var lineErr *LineError
+			if !errors.As(err, &lineErr) {
+				t.Fatalf("checkFields() error = %v, want *LineError", err)
+			}
+			if lineErr.Line != tt.line || lineErr.Message != tt.message {
+				t.Errorf("checkFields() = line %d: %s\nwant line %d: %s", lineErr.Line, lineErr.Message, tt.line, tt.message)
+			}
+		})
+	}
+}
+
+func TestLineAt(t *testing.T) {
+	data := []byte("a\nbc\n\nd")
+	tests := []struct {
+		offset int64
+		want   int
+	}{
+		{offset: 0, want: 1},
+		{offset: 1, want: 1},
+		{offset: 2, want: 2},
+		{offset: 5, want: 3},
+		{offset: 6, want: 4},
+		{offset: 100, want: 4},
+	}
+	for _, tt := range tests {
+		if got := lineAt(data, tt.offset); got != tt.want {
+			t.Errorf("lineAt(%d) = %d, want %d", tt.offset, got, tt.want)
+		}
+	}
+}
+
+func TestSuggestField(t *testing.T) {
+	fields := configSchema.fields["servers"].values.fields
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{key: "comand", want: `"command"`},
+		{key: "URL", want: `"url"`},
+		{key: "header", want: `"headers"`},
+		{key: "completely-different", want: "known fields: args, auth,"},
+	}
+	for _, tt := range tests {
+		if got := suggestField(tt.key, fields); !strings.Contains(got, tt.want) {
+			t.Errorf("suggestField(%q) = %q, want it to contain %q", tt.key, got, tt.want)
+		}
+	}
+}
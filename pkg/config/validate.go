@@ -0,0 +1,201 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Copyright 2025 MCP CLI Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// 校验问题的严重程度
+const (
+	SeverityError   = "error"   // 连接时必然失败
+	SeverityWarning = "warning" // 配置可以使用，但部分字段不会生效
+)
+
+// Transports 支持的传输方式
+var Transports = []string{"stdio", "sse", "http"}
+
+// Issue 服务器配置的一个校验问题
+type Issue struct {
+	Server   string `json:"server"`
+	Path     string `json:"path"` // 服务器所在的配置文件
+	Field    string `json:"field,omitempty"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+// Validate 校验所有服务器配置，按服务器名排序返回发现的问题
+func (cm *ConfigManager) Validate() []Issue {
+	var issues []Issue
+	for _, name := range cm.GetServerNames() {
+		_, path := cm.ServerOrigin(name)
+		for _, issue := range ValidateServer(name, cm.servers[name], cm.resolved[name], cm.unresolved[name]) {
+			issue.Path = path
+			issues = append(issues, issue)
+		}
+	}
+	return issues
+}
+
+// ValidateServer 校验单个服务器配置。server 为原始配置，resolved 为展开引用后的配置，
+// unresolved 为无法解析的引用；URL 和命令的检查使用展开后的值。
+func ValidateServer(name string, server, resolved *ServerConfig, unresolved []string) []Issue {
+	var issues []Issue
+	report := func(severity, field, format string, args ...any) {
+		issues = append(issues, Issue{Server: name, Field: field, Severity: severity, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if server.Name != "" && server.Name != name {
+		report(SeverityError, "name", "name %q does not match the server key %q", server.Name, name)
+	}
+	if len(unresolved) > 0 {
+		report(SeverityError, "", "%v", &UnresolvedError{Server: name, References: unresolved})
+	}
+	if server.MaxRetries < 0 {
+		report(SeverityError, "maxRetries", "maxRetries must not be negative")
+	}
+	for field, d := range map[string]Duration{"connectTimeout": server.ConnectTimeout, "callTimeout": server.CallTimeout, "shutdownGrace": server.ShutdownGrace} {
+		if _, err := d.Value(0); err != nil {
+			report(SeverityError, field, "%v", err)
+		}
+	}
+
+	switch server.Transport {
+	case "stdio":
+		switch {
+		case server.Command == "":
+			report(SeverityError, "command", "command is required for stdio servers")
+		case !strings.Contains(resolved.Command, "${"):
+			if _, err := exec.LookPath(commandPath(resolved.Command, resolved.Cwd)); err != nil {
+				if errors.Is(err, exec.ErrNotFound) {
+					report(SeverityError, "command", "command %q not found in PATH", resolved.Command)
+				} else {
+					report(SeverityError, "command", "command %q is not executable: %v", resolved.Command, err)
+				}
+			}
+		}
+		if server.Cwd != "" && !strings.Contains(resolved.Cwd, "${") {
+			if info, err := os.Stat(resolved.Cwd); err != nil {
+				report(SeverityError, "cwd", "cannot use %s: %v", resolved.Cwd, errors.Unwrap(err))
+			} else if !info.IsDir() {
+				report(SeverityError, "cwd", "%s is not a directory", resolved.Cwd)
+			}
+		}
+		for field, set := range map[string]bool{"url": server.URL != "", "headers": len(server.Headers) > 0, "auth": server.Auth != nil, "tls": server.TLS != nil} {
+			if set {
+				report(SeverityWarning, field, "%s is ignored for stdio servers", field)
+			}
+		}
+
+	case "sse", "http":
+		switch {
+		case server.URL == "":
+			report(SeverityError, "url", "url is required for %s servers", server.Transport)
+		case !strings.Contains(resolved.URL, "${"):
+			if err := checkURL(resolved.URL); err != nil {
+				report(SeverityError, "url", "invalid url %q: %v", resolved.URL, err)
+			}
+		}
+		if server.TLS != nil {
+			checkTLS(server.TLS, resolved.TLS, report)
+		}
+		for field, set := range map[string]bool{
+			"command":       server.Command != "",
+			"args":          len(server.Args) > 0,
+			"env":           len(server.Env) > 0,
+			"cwd":           server.Cwd != "",
+			"inheritEnv":    server.InheritEnv != nil,
+			"shutdownGrace": server.ShutdownGrace != "",
+		} {
+			if set {
+				report(SeverityWarning, field, "%s is ignored for %s servers", field, server.Transport)
+			}
+		}
+
+	case "":
+		report(SeverityError, "transport", "transport is required (valid: %s)", strings.Join(Transports, ", "))
+	default:
+		report(SeverityError, "transport", "unknown transport %q (valid: %s)", server.Transport, strings.Join(Transports, ", "))
+	}
+
+	sortIssues(issues)
+	return issues
+}
+
+// ValidateServerConfig 展开引用后校验尚未保存的服务器配置
+func ValidateServerConfig(name string, server *ServerConfig) []Issue {
+	resolved, unresolved := interpolateServer(server)
+	return ValidateServer(name, server, resolved, unresolved)
+}
+
+// checkTLS 检查客户端证书和私钥是否成对出现，以及证书文件是否可读
+func checkTLS(tls, resolved *TLSConfig, report func(severity, field, format string, args ...any)) {
+	if (tls.CertFile == "") != (tls.KeyFile == "") {
+		report(SeverityError, "tls", "certFile and keyFile must be set together")
+	}
+	for field, path := range map[string]string{"tls.caFile": resolved.CAFile, "tls.certFile": resolved.CertFile, "tls.keyFile": resolved.KeyFile} {
+		if path == "" || strings.Contains(path, "${") {
+			continue
+		}
+		if _, err := os.Stat(path); err != nil {
+			report(SeverityError, field, "cannot read %s: %v", path, errors.Unwrap(err))
+		}
+	}
+	if tls.InsecureSkipVerify {
+		report(SeverityWarning, "tls.insecureSkipVerify", "server certificate is not verified")
+	}
+}
+
+// commandPath 返回检查命令时使用的路径。带路径分隔符的相对命令（如 ./mcp-server）
+// 启动时相对于工作目录解析，检查时也需要以 cwd 为基准；不带分隔符的命令在 PATH 中查找
+func commandPath(command, cwd string) string {
+	if cwd == "" || filepath.IsAbs(command) || !strings.ContainsRune(filepath.ToSlash(command), '/') {
+		return command
+	}
+	return filepath.Join(cwd, command)
+}
+
+// checkURL 检查 URL 是否为带主机名的 http(s) 地址
+func checkURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return errors.Unwrap(err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("scheme must be http or https")
+	}
+	if u.Host == "" {
+		return fmt.Errorf("missing host")
+	}
+	return nil
+}
+
+// sortIssues 错误排在警告之前，同级按字段排序
+func sortIssues(issues []Issue) {
+	rank := func(issue Issue) string {
+		if issue.Severity == SeverityError {
+			return "0" + issue.Field
+		}
+		return "1" + issue.Field
+	}
+	sort.SliceStable(issues, func(i, j int) bool { return rank(issues[i]) < rank(issues[j]) })
+}
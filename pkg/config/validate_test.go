@@ -0,0 +1,63 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Copyright 2025 MCP CLI Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+func TestValidateServerCommand(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	app := filepath.Join(home, "projects", "app")
+	writeFile(t, filepath.Join(app, "mcp-server"), "#!/bin/sh\n")
+	if err := os.Chmod(filepath.Join(app, "mcp-server"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(app, "data.txt"), "")
+
+	tests := []struct {
+		name    string
+		command string
+		cwd     string
+		wantErr bool
+	}{
+		{name: "in PATH", command: "sh"},
+		{name: "absolute", command: filepath.Join(app, "mcp-server")},
+		{name: "relative to cwd", command: "./mcp-server", cwd: "~/projects/app"},
+		{name: "relative subdirectory", command: "app/mcp-server", cwd: filepath.Join(home, "projects")},
+		{name: "relative without cwd", command: "./mcp-server", wantErr: true},
+		{name: "not executable", command: "./data.txt", cwd: app, wantErr: true},
+		{name: "bare name is not looked up in cwd", command: "mcp-server", cwd: app, wantErr: true},
+		{name: "missing", command: "mcp-cli-no-such-command", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &ServerConfig{Transport: "stdio", Command: tt.command, Cwd: tt.cwd}
+			var commandIssues []Issue
+			for _, issue := range ValidateServerConfig("s", server) {
+				if issue.Field == "command" {
+					commandIssues = append(commandIssues, issue)
+				}
+			}
+			if gotErr := len(commandIssues) > 0; gotErr != tt.wantErr {
+				t.Errorf("command issues = %v, want error %v", commandIssues, tt.wantErr)
+			}
+		})
+	}
+}
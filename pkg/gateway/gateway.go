@@ -0,0 +1,285 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/justinwongcn/go-mcp-cli/pkg/client"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Copyright 2025 MCP CLI Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// DefaultSeparator 服务器名与工具名、提示模板名之间的默认分隔符
+const DefaultSeparator = "__"
+
+// Gateway 将多个上游 MCP 服务器聚合为一个 MCP 服务器
+type Gateway struct {
+	server    *mcp.Server
+	separator string
+
+	mu        sync.Mutex
+	upstreams map[string]*client.MCPClient
+	tools     map[string][]string // 上游服务器名 -> 已注册的带前缀工具名
+	resources map[string]string   // 资源 URI -> 上游服务器名
+	templates map[string]string   // 资源模板 -> 上游服务器名
+	warn      func(upstream, message string)
+
+	refreshMu sync.Mutex // 串行化工具列表的刷新，避免较早的列表结果覆盖较新的
+}
+
+// New 创建网关
+func New(name, version, separator string) *Gateway {
+	if separator == "" {
+		separator = DefaultSeparator
+	}
+
+	server := mcp.NewServer(&mcp.Implementation{
+		Name:    name,
+		Version: version,
+	}, nil)
+
+	return &Gateway{
+		server:    server,
+		separator: separator,
+		upstreams: make(map[string]*client.MCPClient),
+		tools:     make(map[string][]string),
+		resources: make(map[string]string),
+		templates: make(map[string]string),
+	}
+}
+
+// Server 返回聚合后的 MCP 服务器
+func (g *Gateway) Server() *mcp.Server {
+	return g.server
+}
+
+// OnWarning 设置上游工具列表刷新时报告问题的函数（如工具无法注册或重新列出失败）。
+// 未设置时这些问题被忽略。
+func (g *Gateway) OnWarning(warn func(upstream, message string)) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.warn = warn
+}
+
+// AddUpstream 注册一个已连接的上游服务器，并将其工具、资源和提示模板加入网关。
+// 工具名和提示模板名会加上 "<服务器名><分隔符>" 前缀；资源 URI 保持不变，
+// 与已注册资源冲突时跳过并在 warnings 中说明。
+// 上游通知工具列表变化时，网关重新列出该上游的工具并更新注册，下游会话随之收到 list_changed 通知。
+func (g *Gateway) AddUpstream(ctx context.Context, name string, cli *client.MCPClient) (warnings []string, err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if _, exists := g.upstreams[name]; exists {
+		return nil, fmt.Errorf("upstream already registered: %s", name)
+	}
+
+	var caps *mcp.ServerCapabilities
+	if info := cli.ServerInfo(); info != nil {
+		caps = info.Capabilities
+	}
+	if caps == nil {
+		caps = &mcp.ServerCapabilities{}
+	}
+
+	if caps.Tools != nil {
+		tools, err := cli.ListTools(ctx)
+		if err != nil {
+			return warnings, fmt.Errorf("failed to list tools: %w", err)
+		}
+		warnings = append(warnings, g.setTools(name, cli, tools.Tools)...)
+		cli.OnToolListChanged(func() {
+			// 通知在上游会话的读取循环中处理，重新列出工具需要在新的 goroutine 中进行
+			go g.refreshTools(name, cli)
+		})
+	}
+
+	if caps.Resources != nil {
+		resources, err := cli.ListResources(ctx)
+		if err != nil {
+			return warnings, fmt.Errorf("failed to list resources: %w", err)
+		}
+		for _, resource := range resources.Resources {
+			if owner, exists := g.resources[resource.URI]; exists {
+				warnings = append(warnings, fmt.Sprintf("resource %s already provided by %s, skipping", resource.URI, owner))
+				continue
+			}
+			g.resources[resource.URI] = name
+			g.server.AddResource(resource, readHandler(cli))
+		}
+
+		templates, err := cli.ListResourceTemplates(ctx)
+		if err != nil {
+			return warnings, fmt.Errorf("failed to list resource templates: %w", err)
+		}
+		for _, template := range templates.ResourceTemplates {
+			if owner, exists := g.templates[template.URITemplate]; exists {
+				warnings = append(warnings, fmt.Sprintf("resource template %s already provided by %s, skipping", template.URITemplate, owner))
+				continue
+			}
+			g.templates[template.URITemplate] = name
+			g.server.AddResourceTemplate(template, readHandler(cli))
+		}
+	}
+
+	if caps.Prompts != nil {
+		prompts, err := cli.ListPrompts(ctx)
+		if err != nil {
+			return warnings, fmt.Errorf("failed to list prompts: %w", err)
+		}
+		for _, prompt := range prompts.Prompts {
+			upstreamName := prompt.Name
+			exposed := *prompt
+			exposed.Name = g.prefixed(name, prompt.Name)
+			g.server.AddPrompt(&exposed, func(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+				return cli.GetPrompt(ctx, upstreamName, req.Params.Arguments)
+			})
+		}
+	}
+
+	g.upstreams[name] = cli
+	return warnings, nil
+}
+
+// refreshTools 重新列出上游的工具并更新注册
+func (g *Gateway) refreshTools(name string, cli *client.MCPClient) {
+	g.refreshMu.Lock()
+	defer g.refreshMu.Unlock()
+
+	tools, err := cli.ListTools(context.Background())
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.upstreams[name] != cli {
+		return // 上游已关闭
+	}
+	var warnings []string
+	if err != nil {
+		warnings = []string{fmt.Sprintf("failed to refresh tools: %v", err)}
+	} else {
+		warnings = g.setTools(name, cli, tools.Tools)
+	}
+	if g.warn != nil {
+		for _, w := range warnings {
+			g.warn(name, w)
+		}
+	}
+}
+
+// setTools 用上游当前的工具列表替换已注册的工具，返回无法注册的工具的警告信息。
+// 调用方需持有 g.mu。
+func (g *Gateway) setTools(upstream string, cli *client.MCPClient, tools []*mcp.Tool) []string {
+	var warnings []string
+	registered := make(map[string]bool, len(tools))
+	for _, tool := range tools {
+		if w := g.addTool(upstream, cli, tool); w != "" {
+			warnings = append(warnings, w)
+			continue
+		}
+		registered[g.prefixed(upstream, tool.Name)] = true
+	}
+
+	// 同名工具由 AddTool 直接替换，只需移除上游不再提供的工具
+	var removed []string
+	for _, exposed := range g.tools[upstream] {
+		if !registered[exposed] {
+			removed = append(removed, exposed)
+		}
+	}
+	if len(removed) > 0 {
+		g.server.RemoveTools(removed...)
+	}
+
+	names := make([]string, 0, len(registered))
+	for exposed := range registered {
+		names = append(names, exposed)
+	}
+	g.tools[upstream] = names
+	return warnings
+}
+
+// addTool 注册一个带前缀的上游工具，无法注册时返回警告信息
+func (g *Gateway) addTool(upstream string, cli *client.MCPClient, tool *mcp.Tool) string {
+	exposed := *tool
+	exposed.Name = g.prefixed(upstream, tool.Name)
+
+	// mcp.Server.AddTool 要求 schema 的类型为 object，否则会 panic
+	if exposed.InputSchema == nil {
+		exposed.InputSchema = map[string]any{"type": "object"}
+	}
+	if !isObjectSchema(exposed.InputSchema) {
+		return fmt.Sprintf("tool %s has a non-object input schema, skipping", exposed.Name)
+	}
+	if exposed.OutputSchema != nil && !isObjectSchema(exposed.OutputSchema) {
+		exposed.OutputSchema = nil
+	}
+
+	upstreamName := tool.Name
+	g.server.AddTool(&exposed, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var args map[string]any
+		if len(req.Params.Arguments) > 0 {
+			if err := json.Unmarshal(req.Params.Arguments, &args); err != nil {
+				return nil, fmt.Errorf("invalid arguments: %w", err)
+			}
+		}
+		return cli.CallTool(ctx, upstreamName, args)
+	})
+	return ""
+}
+
+// prefixed 返回带服务器名前缀的名称
+func (g *Gateway) prefixed(upstream, name string) string {
+	return upstream + g.separator + name
+}
+
+// Close 关闭所有上游连接
+func (g *Gateway) Close() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	var firstErr error
+	for name, cli := range g.upstreams {
+		cli.OnToolListChanged(nil)
+		if err := cli.Close(); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("failed to close %s: %w", name, err)
+		}
+	}
+	g.upstreams = make(map[string]*client.MCPClient)
+	g.tools = make(map[string][]string)
+	return firstErr
+}
+
+// readHandler 返回将资源读取请求转发到上游的处理函数
+func readHandler(cli *client.MCPClient) mcp.ResourceHandler {
+	return func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+		return cli.ReadResource(ctx, req.Params.URI)
+	}
+}
+
+// isObjectSchema 检查 JSON Schema 的类型是否为 object
+func isObjectSchema(schema any) bool {
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return false
+	}
+	var m map[string]any
+	if err := json.Unmarshal(data, &m); err != nil {
+		return false
+	}
+	return m["type"] == "object"
+}
@@ -0,0 +1,115 @@
+package gateway
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/justinwongcn/go-mcp-cli/pkg/client"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Copyright 2025 MCP CLI Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+func TestGatewayForwardsToolListChanges(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	upstream := mcp.NewServer(&mcp.Implementation{Name: "upstream", Version: "1.0.0"}, nil)
+	addTool(upstream, "a")
+	addTool(upstream, "b")
+	httpServer := httptest.NewServer(mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return upstream }, nil))
+	defer httpServer.Close()
+
+	cli := client.NewClient("test", "1.0.0")
+	if err := cli.ConnectHTTP(ctx, &client.HTTPConfig{Endpoint: httpServer.URL}); err != nil {
+		t.Fatal(err)
+	}
+
+	gw := New("gateway", "1.0.0", "")
+	defer gw.Close()
+	if _, err := gw.AddUpstream(ctx, "up", cli); err != nil {
+		t.Fatal(err)
+	}
+
+	// 下游客户端通过内存传输连接网关
+	changed := make(chan struct{}, 10)
+	downstream := mcp.NewClient(&mcp.Implementation{Name: "downstream", Version: "1.0.0"}, &mcp.ClientOptions{
+		ToolListChangedHandler: func(context.Context, *mcp.ToolListChangedRequest) { changed <- struct{}{} },
+	})
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	if _, err := gw.Server().Connect(ctx, serverTransport, nil); err != nil {
+		t.Fatal(err)
+	}
+	session, err := downstream.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer session.Close()
+
+	if got := toolNames(t, ctx, session); !slices.Equal(got, []string{"up__a", "up__b"}) {
+		t.Fatalf("tools = %v, want [up__a up__b]", got)
+	}
+
+	// 上游移除 a、增加 c
+	upstream.RemoveTools("a")
+	addTool(upstream, "c")
+
+	want := []string{"up__b", "up__c"}
+	var got []string
+	for !slices.Equal(got, want) {
+		select {
+		case <-changed:
+			got = toolNames(t, ctx, session)
+		case <-ctx.Done():
+			t.Fatalf("tools = %v, want %v after list_changed", got, want)
+		}
+	}
+
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "up__c"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text, ok := result.Content[0].(*mcp.TextContent); !ok || text.Text != "c" {
+		t.Errorf("CallTool(up__c) = %+v, want the upstream result", result.Content)
+	}
+}
+
+// addTool 在上游服务器上注册一个返回自身名称的工具
+func addTool(server *mcp.Server, name string) {
+	server.AddTool(&mcp.Tool{Name: name, InputSchema: map[string]any{"type": "object"}},
+		func(context.Context, *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: name}}}, nil
+		})
+}
+
+// toolNames 返回下游会话看到的工具名，按名称排序
+func toolNames(t *testing.T, ctx context.Context, session *mcp.ClientSession) []string {
+	t.Helper()
+	result, err := session.ListTools(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, tool := range result.Tools {
+		names = append(names, tool.Name)
+	}
+	slices.Sort(names)
+	return names
+}
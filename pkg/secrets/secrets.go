@@ -0,0 +1,348 @@
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/justinwongcn/go-mcp-cli/pkg/config"
+	"github.com/justinwongcn/go-mcp-cli/pkg/fileutil"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// Copyright 2025 MCP CLI Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// PassphraseEnv 设置后使用口令加密密钥存储，否则使用密钥文件
+const PassphraseEnv = "MCP_CLI_SECRETS_PASSPHRASE"
+
+// 加密密钥的来源
+const (
+	kdfKeyfile = "keyfile"
+	kdfScrypt  = "scrypt"
+)
+
+// ErrNotFound 密钥不存在
+var ErrNotFound = errors.New("secret not found")
+
+// namePattern 合法的密钥名
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// refPattern 配置中的密钥引用，如 ${secret:github_token}
+var refPattern = regexp.MustCompile(`\$\{secret:([^}]*)\}`)
+
+// encryptedFile 密钥存储文件格式，Data 为 AES-256-GCM 加密的 JSON 对象
+type encryptedFile struct {
+	Version int    `json:"version"`
+	KDF     string `json:"kdf"`
+	Salt    string `json:"salt,omitempty"`
+	Nonce   string `json:"nonce"`
+	Data    string `json:"data"`
+}
+
+// Store 加密的密钥存储。
+//
+// 密钥保存在 secrets.enc 中，使用 AES-256-GCM 加密。加密密钥来自
+// MCP_CLI_SECRETS_PASSPHRASE 环境变量（经 scrypt 派生），未设置时使用
+// 同目录下随机生成的 secrets.key 密钥文件。修改在文件锁内读取最新内容后进行并原子写回，
+// 多个 mcp-cli 进程同时修改不会互相覆盖，也不会各自生成不同的密钥文件。
+type Store struct {
+	path    string
+	keyPath string
+}
+
+// NewStore 创建密钥存储，dir 为空时使用用户配置目录下的 mcp-cli
+func NewStore(dir string) (*Store, error) {
+	if dir == "" {
+		configDir, err := config.UserConfigDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to locate user config directory: %w", err)
+		}
+		dir = configDir
+	}
+	return &Store{
+		path:    filepath.Join(dir, "secrets.enc"),
+		keyPath: filepath.Join(dir, "secrets.key"),
+	}, nil
+}
+
+// Get 读取密钥
+func (s *Store) Get(name string) (string, error) {
+	all, err := s.load()
+	if err != nil {
+		return "", err
+	}
+	value, ok := all[name]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	return value, nil
+}
+
+// Set 保存密钥
+func (s *Store) Set(name, value string) error {
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("invalid secret name %q (allowed: letters, digits, '_', '.', '-')", name)
+	}
+	return s.update(func(all map[string]string) bool {
+		all[name] = value
+		return true
+	})
+}
+
+// Delete 删除密钥，不存在时返回 false
+func (s *Store) Delete(name string) (bool, error) {
+	removed := false
+	err := s.update(func(all map[string]string) bool {
+		_, removed = all[name]
+		delete(all, name)
+		return removed
+	})
+	return removed, err
+}
+
+// update 在文件锁内重新读取所有密钥并应用修改，mutate 返回 true 时加密写回
+func (s *Store) update(mutate func(all map[string]string) bool) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("failed to create secrets directory: %w", err)
+	}
+	release, err := fileutil.Lock(s.path + ".lock")
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	all, err := s.load()
+	if err != nil {
+		return err
+	}
+	if !mutate(all) {
+		return nil
+	}
+	return s.save(all)
+}
+
+// Names 返回所有密钥名（已排序）
+func (s *Store) Names() ([]string, error) {
+	all, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(all))
+	for name := range all {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// load 读取并解密所有密钥，文件不存在时返回空集合
+func (s *Store) load() (map[string]string, error) {
+	all := make(map[string]string)
+
+	raw, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return all, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secrets: %w", err)
+	}
+
+	var file encryptedFile
+	if err := json.Unmarshal(raw, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse secrets file %s: %w", s.path, err)
+	}
+	salt, err := hex.DecodeString(file.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("corrupt secrets file: %w", err)
+	}
+	nonce, err := hex.DecodeString(file.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("corrupt secrets file: %w", err)
+	}
+	data, err := hex.DecodeString(file.Data)
+	if err != nil {
+		return nil, fmt.Errorf("corrupt secrets file: %w", err)
+	}
+
+	key, err := s.key(file.KDF, salt, false)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := gcm.Open(nil, nonce, data, nil)
+	if err != nil {
+		if file.KDF == kdfScrypt {
+			return nil, fmt.Errorf("failed to decrypt secrets: wrong passphrase")
+		}
+		return nil, fmt.Errorf("failed to decrypt secrets: key file does not match")
+	}
+	if err := json.Unmarshal(plain, &all); err != nil {
+		return nil, fmt.Errorf("failed to parse decrypted secrets: %w", err)
+	}
+	return all, nil
+}
+
+// save 加密并写入所有密钥，沿用已有文件的加密方式。调用方需持有文件锁。
+func (s *Store) save(all map[string]string) error {
+	kdf := kdfKeyfile
+	if os.Getenv(PassphraseEnv) != "" {
+		kdf = kdfScrypt
+	}
+	if raw, err := os.ReadFile(s.path); err == nil {
+		var file encryptedFile
+		if json.Unmarshal(raw, &file) == nil && file.KDF != "" {
+			kdf = file.KDF
+		}
+	}
+
+	var salt []byte
+	if kdf == kdfScrypt {
+		var err error
+		if salt, err = randomBytes(16); err != nil {
+			return err
+		}
+	}
+	key, err := s.key(kdf, salt, true)
+	if err != nil {
+		return err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return err
+	}
+
+	plain, err := json.Marshal(all)
+	if err != nil {
+		return fmt.Errorf("failed to encode secrets: %w", err)
+	}
+	nonce, err := randomBytes(gcm.NonceSize())
+	if err != nil {
+		return err
+	}
+	file := encryptedFile{
+		Version: 1,
+		KDF:     kdf,
+		Salt:    hex.EncodeToString(salt),
+		Nonce:   hex.EncodeToString(nonce),
+		Data:    hex.EncodeToString(gcm.Seal(nil, nonce, plain, nil)),
+	}
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode secrets: %w", err)
+	}
+
+	if err := fileutil.WriteAtomic(s.path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write secrets: %w", err)
+	}
+	return nil
+}
+
+// key 返回加密密钥。create 为 true 时密钥文件不存在会自动生成，调用方需持有文件锁。
+func (s *Store) key(kdf string, salt []byte, create bool) ([]byte, error) {
+	switch kdf {
+	case kdfScrypt:
+		passphrase := os.Getenv(PassphraseEnv)
+		if passphrase == "" {
+			return nil, fmt.Errorf("secrets are passphrase-protected, set %s", PassphraseEnv)
+		}
+		return scrypt.Key([]byte(passphrase), salt, 1<<15, 8, 1, 32)
+
+	case kdfKeyfile:
+		raw, err := os.ReadFile(s.keyPath)
+		if os.IsNotExist(err) && create {
+			key, err := randomBytes(32)
+			if err != nil {
+				return nil, err
+			}
+			// 原子写入，不加锁的读取方不会读到写了一半的密钥文件
+			if err := fileutil.WriteAtomic(s.keyPath, []byte(hex.EncodeToString(key)+"\n"), 0600); err != nil {
+				return nil, fmt.Errorf("failed to write key file: %w", err)
+			}
+			return key, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read key file: %w", err)
+		}
+		key, err := hex.DecodeString(strings.TrimSpace(string(raw)))
+		if err != nil || len(key) != 32 {
+			return nil, fmt.Errorf("invalid key file %s", s.keyPath)
+		}
+		return key, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported secrets encryption: %s", kdf)
+	}
+}
+
+// Resolve 将字符串中的 ${secret:name} 引用替换为密钥的值
+func (s *Store) Resolve(value string) (string, error) {
+	if !strings.Contains(value, "${secret:") {
+		return value, nil
+	}
+
+	all, err := s.load()
+	if err != nil {
+		return "", err
+	}
+
+	var missing []string
+	resolved := refPattern.ReplaceAllStringFunc(value, func(ref string) string {
+		name := refPattern.FindStringSubmatch(ref)[1]
+		secret, ok := all[name]
+		if !ok {
+			missing = append(missing, name)
+			return ref
+		}
+		return secret
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("%w: %s", ErrNotFound, strings.Join(missing, ", "))
+	}
+	return resolved, nil
+}
+
+// HasReference 判断字符串中是否包含密钥引用
+func HasReference(value string) bool {
+	return refPattern.MatchString(value)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func randomBytes(n int) ([]byte, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, fmt.Errorf("failed to generate random bytes: %w", err)
+	}
+	return buf, nil
+}
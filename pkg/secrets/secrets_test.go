@@ -0,0 +1,215 @@
+package secrets
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Copyright 2025 MCP CLI Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+func TestStoreRoundTrip(t *testing.T) {
+	tests := []struct {
+		name       string
+		passphrase string
+		kdf        string
+	}{
+		{name: "key file", kdf: kdfKeyfile},
+		{name: "passphrase", passphrase: "correct horse", kdf: kdfScrypt},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(PassphraseEnv, tt.passphrase)
+			dir := t.TempDir()
+			store, err := NewStore(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := store.Set("token", "s3cret value"); err != nil {
+				t.Fatalf("Set(): %v", err)
+			}
+			if err := store.Set("other", "x"); err != nil {
+				t.Fatalf("Set(): %v", err)
+			}
+
+			// 新建的 Store 从磁盘读取，验证加密后能正确解密
+			reopened, _ := NewStore(dir)
+			if got, err := reopened.Get("token"); err != nil || got != "s3cret value" {
+				t.Fatalf("Get() = %q, %v, want %q", got, err, "s3cret value")
+			}
+			if names, err := reopened.Names(); err != nil || !reflect.DeepEqual(names, []string{"other", "token"}) {
+				t.Errorf("Names() = %q, %v", names, err)
+			}
+
+			raw, err := os.ReadFile(filepath.Join(dir, "secrets.enc"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Contains(string(raw), "s3cret") {
+				t.Error("secrets file contains the plaintext value")
+			}
+			if !strings.Contains(string(raw), `"kdf": "`+tt.kdf+`"`) {
+				t.Errorf("secrets file does not use kdf %s: %s", tt.kdf, raw)
+			}
+			info, err := os.Stat(filepath.Join(dir, "secrets.enc"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if perm := info.Mode().Perm(); perm != 0600 {
+				t.Errorf("secrets file mode = %o, want 600", perm)
+			}
+			_, keyErr := os.Stat(filepath.Join(dir, "secrets.key"))
+			if hasKey := keyErr == nil; hasKey != (tt.kdf == kdfKeyfile) {
+				t.Errorf("key file exists = %v with kdf %s", hasKey, tt.kdf)
+			}
+
+			removed, err := reopened.Delete("token")
+			if err != nil || !removed {
+				t.Fatalf("Delete() = %v, %v", removed, err)
+			}
+			if _, err := store.Get("token"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Get() after delete error = %v, want ErrNotFound", err)
+			}
+			if removed, err := store.Delete("token"); err != nil || removed {
+				t.Errorf("Delete() of missing secret = %v, %v", removed, err)
+			}
+		})
+	}
+}
+
+func TestStoreWrongKey(t *testing.T) {
+	t.Run("passphrase", func(t *testing.T) {
+		dir := t.TempDir()
+		t.Setenv(PassphraseEnv, "right")
+		store, _ := NewStore(dir)
+		if err := store.Set("a", "1"); err != nil {
+			t.Fatal(err)
+		}
+
+		t.Setenv(PassphraseEnv, "wrong")
+		if _, err := store.Get("a"); err == nil || !strings.Contains(err.Error(), "wrong passphrase") {
+			t.Errorf("Get() with wrong passphrase error = %v", err)
+		}
+		t.Setenv(PassphraseEnv, "")
+		if _, err := store.Get("a"); err == nil || !strings.Contains(err.Error(), PassphraseEnv) {
+			t.Errorf("Get() without passphrase error = %v", err)
+		}
+	})
+
+	t.Run("key file", func(t *testing.T) {
+		dir := t.TempDir()
+		t.Setenv(PassphraseEnv, "")
+		store, _ := NewStore(dir)
+		if err := store.Set("a", "1"); err != nil {
+			t.Fatal(err)
+		}
+
+		other := strings.Repeat("ab", 32) + "\n"
+		if err := os.WriteFile(filepath.Join(dir, "secrets.key"), []byte(other), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := store.Get("a"); err == nil || !strings.Contains(err.Error(), "key file does not match") {
+			t.Errorf("Get() with replaced key file error = %v", err)
+		}
+	})
+}
+
+func TestStoreConcurrentSet(t *testing.T) {
+	t.Setenv(PassphraseEnv, "")
+	dir := t.TempDir()
+
+	const n = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// 每个 goroutine 使用独立的 Store，模拟多个进程
+			store, _ := NewStore(dir)
+			errs <- store.Set(string(rune('a'+i)), "v")
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("Set(): %v", err)
+		}
+	}
+
+	store, _ := NewStore(dir)
+	names, err := store.Names()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != n {
+		t.Errorf("Names() = %q, want %d secrets", names, n)
+	}
+}
+
+func TestSetInvalidName(t *testing.T) {
+	store, _ := NewStore(t.TempDir())
+	for _, name := range []string{"", "a b", "a/b", "${x}"} {
+		if err := store.Set(name, "v"); err == nil {
+			t.Errorf("Set(%q) succeeded, want invalid name error", name)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	t.Setenv(PassphraseEnv, "")
+	store, _ := NewStore(t.TempDir())
+	if err := store.Set("token", "abc"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Set("user.name", "ann"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		in      string
+		want    string
+		wantErr string
+	}{
+		{in: "plain", want: "plain"},
+		{in: "Bearer ${secret:token}", want: "Bearer abc"},
+		{in: "${secret:user.name}:${secret:token}", want: "ann:abc"},
+		{in: "${env:HOME}", want: "${env:HOME}"},
+		{in: "${secret:missing} ${secret:gone}", wantErr: "secret not found: missing, gone"},
+	}
+	for _, tt := range tests {
+		got, err := store.Resolve(tt.in)
+		if tt.wantErr != "" {
+			if err == nil || !errors.Is(err, ErrNotFound) || err.Error() != tt.wantErr {
+				t.Errorf("Resolve(%q) error = %v, want %q", tt.in, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("Resolve(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+	}
+
+	if !HasReference("x ${secret:a}") || HasReference("${env:a}") {
+		t.Error("HasReference() mismatch")
+	}
+}